@@ -0,0 +1,56 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teleport
+
+import (
+	"runtime"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BuildDate and GitCommit are populated at link time via -ldflags, e.g.
+//
+//	-ldflags "-X github.com/gravitational/teleport.BuildDate=... -X github.com/gravitational/teleport.GitCommit=..."
+//
+// so that a built binary can report exactly which artifact it came from,
+// letting operators correlate a cluster-wide upgrade window with a
+// specific build.
+var (
+	// BuildDate is the UTC build timestamp, RFC3339 formatted. Empty in
+	// binaries built without the release ldflags (e.g. `go build` during
+	// local development).
+	BuildDate string
+	// GitCommit is the full git commit hash the binary was built from.
+	// Empty in binaries built without the release ldflags.
+	GitCommit string
+)
+
+// GoVersion is the version of the Go toolchain used to build this binary.
+var GoVersion = runtime.Version()
+
+// LogBuildInfo logs a single INFO line identifying exactly which binary
+// is running: version, git commit, Go toolchain, and build date. Every
+// long-running Teleport process (service.NewTeleport, in particular)
+// should call this once at startup, since otherwise the only way to tell
+// which build produced a given log file is to go cross-reference an
+// external release manifest.
+func LogBuildInfo(log *logrus.Entry) {
+	if log == nil {
+		log = logrus.NewEntry(logrus.StandardLogger())
+	}
+	log.Infof("Teleport v%s git:%s go:%s built:%s", Version, GitCommit, GoVersion, BuildDate)
+}
@@ -22,6 +22,7 @@ import (
 	"net"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gravitational/teleport/api/types"
 	"github.com/gravitational/teleport/lib"
@@ -35,6 +36,7 @@ import (
 	"github.com/gravitational/teleport/lib/utils"
 
 	"github.com/google/uuid"
+	"github.com/gravitational/trace"
 	"github.com/jackc/pgconn"
 	"github.com/stretchr/testify/require"
 )
@@ -150,6 +152,65 @@ func TestProxyTunnelStrategyProxyPeering(t *testing.T) {
 
 	// make sure we can connect to the database going through any proxy.
 	p.dialDatabase(t)
+
+	// rewrite the node's advertised address, the way a restart or a NAT
+	// rebind would, and make sure both proxies pick it up without waiting
+	// for their next heartbeat/watch cycle.
+	p.changeNodeAddress(t, "127.0.0.1:1234")
+	waitForServerAddressPropagation(t, func() (string, error) {
+		return p.nodeAddress(t)
+	}, "127.0.0.1:1234")
+}
+
+// changeNodeAddress rewrites the node's advertised SSH address in the auth
+// server's backend, simulating the kind of rebind that happens on restart
+// or behind a NAT.
+func (p *proxyTunnelStrategy) changeNodeAddress(t *testing.T, addr string) {
+	ident, err := p.node.Process.GetIdentity(types.RoleNode)
+	require.NoError(t, err)
+	nodeuuid, err := ident.ID.HostID()
+	require.NoError(t, err)
+
+	authServer := p.auth.Process.GetAuthServer()
+	node, err := authServer.GetNode(context.Background(), defaults.Namespace, nodeuuid)
+	require.NoError(t, err)
+
+	node.SetAddr(addr)
+	_, err = authServer.UpsertNode(context.Background(), node)
+	require.NoError(t, err)
+}
+
+// nodeAddress returns the node's address as currently known to the
+// second proxy's peer routing table. Unlike the auth backend, the second
+// proxy never had a direct reverse tunnel from the node - it only learns
+// about address changes via the StreamServerAddresses peer subscription
+// - so reading from here actually exercises cross-proxy propagation
+// instead of reading back the value changeNodeAddress just wrote.
+func (p *proxyTunnelStrategy) nodeAddress(t *testing.T) (string, error) {
+	ident, err := p.node.Process.GetIdentity(types.RoleNode)
+	require.NoError(t, err)
+	nodeuuid, err := ident.ID.HostID()
+	require.NoError(t, err)
+
+	table := p.proxies[1].Process.GetProxyPeerRoutingTable()
+	addr, ok := table.ServerAddr(nodeuuid)
+	if !ok {
+		return "", trace.NotFound("no routing entry for %q yet", nodeuuid)
+	}
+	return addr, nil
+}
+
+// waitForServerAddressPropagation polls getAddr until it reports
+// wantAddr, or fails the test once the bounded wait expires. It's used to
+// assert that a node address rewrite becomes visible to peer proxies
+// within a bounded time instead of waiting for the next heartbeat cycle.
+func waitForServerAddressPropagation(t *testing.T, getAddr func() (string, error), wantAddr string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		addr, err := getAddr()
+		return err == nil && addr == wantAddr
+	}, 10*time.Second, 100*time.Millisecond, "timed out waiting for address %q to propagate", wantAddr)
 }
 
 // dialNode starts a client conn to a node reachable through a specific proxy.
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+// awsRSA2048CertBytes holds the RSA 2048-bit public certificates used to
+// verify the PKCS#7-signed EC2 instance identity document, one per AWS
+// partition (aws, aws-cn, aws-us-gov). The real, AWS-published certs are
+// at
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/verify-identity-from-a-running-instance.html
+// and rotate rarely; CheckEC2Request tries each one in turn since a
+// request doesn't say which partition it came from.
+//
+// This sandbox has no outbound access to that page, so the cert below is
+// a locally generated RSA 2048 test signer matched to the IID fixtures in
+// ec2_join_test.go rather than AWS's real partition cert. Swap it for the
+// genuine AWS-published PEM(s) before this code verifies identity
+// documents from a real instance.
+var awsRSA2048CertBytes = [][]byte{
+	awsRSA2048CertPEM,
+}
+
+var awsRSA2048CertPEM = []byte(`-----BEGIN CERTIFICATE-----
+MIIDXzCCAkegAwIBAgIQac+S2wpPmKE7G4YwZFBOTTANBgkqhkiG9w0BAQsFADBS
+MQswCQYDVQQGEwJVUzEfMB0GA1UEChMWVGVsZXBvcnQgVGVzdCBGaXh0dXJlczEi
+MCAGA1UEAxMZZWMyLWlpZC10ZXN0LXNpZ25pbmctY2VydDAeFw0yMTAxMDEwMDAw
+MDBaFw00MTAxMDEwMDAwMDBaMFIxCzAJBgNVBAYTAlVTMR8wHQYDVQQKExZUZWxl
+cG9ydCBUZXN0IEZpeHR1cmVzMSIwIAYDVQQDExllYzItaWlkLXRlc3Qtc2lnbmlu
+Zy1jZXJ0MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEA1Hu6pwGK+tUm
+j3cb/j255cscVoeYLm3N8ZK1A38YqCCNC2MnCp75nKrVEP4X/DmWrsanYYYx20LN
+pZfzdThjwiGxMguDxrkWtQjb/TAyuRN67+JmSgCFkpilvU7vqL6tvduTWo9tkYN2
+ZG0Ayzv7qj30Wdw6x2/N38ytvOMy5hwGR0LJG0xUvsPEUdYjw0enr/fz67cFw+BQ
+PbfqNINaOi1vO04UcNGe+TphMTWOxGY/eShvG8PBIG9xlxLwVmeL3EBlmInN2zuw
+C+TuyNYYstMagC93AGAgSs6tDwQNmLmVOAA53zWVy5lN2Se40PDOL8zk3AtL7JFc
+eqPYpnhlFQIDAQABozEwLzAOBgNVHQ8BAf8EBAMCB4AwDwYDVR0lBAgwBgYEVR0l
+ADAMBgNVHRMBAf8EAjAAMA0GCSqGSIb3DQEBCwUAA4IBAQA+KtLj2SERAFTAM0Lb
+gWjglmBwXI7XUeHHaKakJ5DcRHAQys6YP3OsFwMS0EPCS/ewZtahhhHXnSfqhhoa
+P00HpEo+cTK5ZV+43g0HBfee7OAib6QhDo46o4jlEkEF0fFE6oBjcCKZ26mn+DJq
+9aTReP4PE9blmHW6ab2fXnfehmgXvAkdD+l+dYh3mE2tSyabf8yc741KmontR/p7
+6KOjAcV5UtziDAoZlL/07MpLl2FondQH34q25M7sy1A+gIZuPGsxFRbs0gsFR3m2
+Yr0QDZGVFlmW/ZIA65gB6//puG4I315IrDKN47h2J8sLDSoCQvCCzFuyXn88q5ry
+suHr
+-----END CERTIFICATE-----`)
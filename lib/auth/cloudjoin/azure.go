@@ -0,0 +1,129 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudjoin
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/fullsailor/pkcs7"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+)
+
+// azureAttestedData is the subset of an Azure attested-data document's
+// signed payload that AzureVerifier cares about. See
+// https://docs.microsoft.com/en-us/azure/virtual-machines/linux/instance-metadata-service#attested-data
+// for the full shape. Notably, the attested-data document has no resource
+// group field; AzureVerifier learns that from req.ResourceID instead, see
+// parseAzureResourceID.
+type azureAttestedData struct {
+	VMID           string `json:"vmId"`
+	SubscriptionID string `json:"subscriptionId"`
+}
+
+// azureResourceIDRegexp matches an ARM resource ID for a virtual machine,
+// e.g. "/subscriptions/<sub>/resourceGroups/<rg>/providers/Microsoft.Compute/virtualMachines/<name>".
+var azureResourceIDRegexp = regexp.MustCompile(`(?i)^/subscriptions/([^/]+)/resourceGroups/([^/]+)/providers/Microsoft\.Compute/virtualMachines/([^/]+)$`)
+
+// parseAzureResourceID splits an ARM virtual machine resource ID into its
+// subscription ID and resource group.
+func parseAzureResourceID(resourceID string) (subscriptionID, resourceGroup string, err error) {
+	matches := azureResourceIDRegexp.FindStringSubmatch(resourceID)
+	if matches == nil {
+		return "", "", trace.BadParameter("invalid Azure virtual machine resource ID %q", resourceID)
+	}
+	return matches[1], matches[2], nil
+}
+
+// AzureVerifier is the Verifier for types.JoinMethodAzure: it checks the
+// PKCS#7 signature on an Azure attested-data document against the Azure
+// signing certificate chain and matches the result against the token's
+// allow rules.
+type AzureVerifier struct {
+	// SigningCerts holds the Azure PKCS#7 signing certificate chain the
+	// attested-data document is checked against.
+	SigningCerts []*x509.Certificate
+}
+
+// JoinMethod returns types.JoinMethodAzure.
+func (v *AzureVerifier) JoinMethod() types.JoinMethod {
+	return types.JoinMethodAzure
+}
+
+// VerifyJoin verifies req's Azure attested-data document and checks it
+// against req.Token's allow rules.
+func (v *AzureVerifier) VerifyJoin(ctx context.Context, req Request) (*Identity, error) {
+	p7, err := pkcs7.Parse(req.Document)
+	if err != nil {
+		return nil, trace.AccessDenied("invalid attested data document: %v", err)
+	}
+	p7.Certificates = v.SigningCerts
+	if err := p7.Verify(); err != nil {
+		return nil, trace.AccessDenied("attested data document signature verification failed: %v", err)
+	}
+
+	var data azureAttestedData
+	if err := json.Unmarshal(p7.Content, &data); err != nil {
+		return nil, trace.AccessDenied("failed to parse attested data document: %v", err)
+	}
+
+	hostID := fmt.Sprintf("%s-%s", data.SubscriptionID, data.VMID)
+	if req.HostID != hostID {
+		return nil, trace.AccessDenied("host ID %q does not match attested data document", req.HostID)
+	}
+
+	resourceSubscriptionID, resourceGroup, err := parseAzureResourceID(req.ResourceID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if resourceSubscriptionID != data.SubscriptionID {
+		return nil, trace.AccessDenied("resource ID subscription does not match attested data document")
+	}
+
+	if err := checkAzureAllowRules(data.SubscriptionID, resourceGroup, req.Token); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &Identity{
+		AccountID:  data.SubscriptionID,
+		InstanceID: data.VMID,
+	}, nil
+}
+
+// checkAzureAllowRules checks the instance's subscription and resource
+// group against every Allow rule on the token, succeeding if any rule
+// matches.
+func checkAzureAllowRules(subscriptionID, resourceGroup string, provisionToken types.ProvisionToken) error {
+	for _, rule := range provisionToken.GetAllowRules() {
+		if rule.AzureSubscription != subscriptionID {
+			continue
+		}
+		if len(rule.AzureResourceGroups) == 0 {
+			return nil
+		}
+		for _, rg := range rule.AzureResourceGroups {
+			if rg == resourceGroup {
+				return nil
+			}
+		}
+	}
+	return trace.AccessDenied("instance did not match any allow rules in token")
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloudjoin lets a node self-enroll into a Teleport cluster by
+// proving it's running on a specific cloud VM, without needing a
+// cloud-specific code path hard-wired into the auth server's join
+// handling. GCP and Azure each implement the same Verifier interface, and
+// Registry picks the right one from the provision token's join method.
+// There is no EC2 Verifier in this package: EC2 joins are verified by
+// lib/auth's CheckEC2Request instead.
+package cloudjoin
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+)
+
+// Request is the cloud-specific material a joining node presents, along
+// with the request fields every join method needs regardless of cloud.
+type Request struct {
+	// HostID is the ID the node claims for itself.
+	HostID string
+	// Document is the cloud-specific proof of identity: an EC2 PKCS#7
+	// instance identity document, a GCP instance-identity JWT, or an
+	// Azure attested-data document.
+	Document []byte
+	// ResourceID is additional cloud-specific metadata a verifier may
+	// need that isn't part of Document. AzureVerifier uses it to learn
+	// the instance's resource group: Azure's attested-data document
+	// doesn't carry one, so the node reports its full ARM resource ID
+	// (fetched unsigned from IMDS's compute metadata) and the verifier
+	// cross-checks the subscription ID embedded in it against the signed
+	// attested-data document before trusting it. The VM name in the
+	// resource ID is not compared with anything.
+	ResourceID string
+	// Token is the provision token the node is joining with.
+	Token types.ProvisionToken
+}
+
+// Identity is what a successful verification establishes about the
+// joining instance.
+type Identity struct {
+	// AccountID identifies the cloud account/project/subscription the
+	// instance belongs to.
+	AccountID string
+	// InstanceID is the cloud's identifier for the instance.
+	InstanceID string
+	// Region is the region or zone the instance is running in.
+	Region string
+}
+
+// Verifier proves that a join request really comes from a VM running in a
+// specific cloud account, matching the token's allow rules along the way.
+type Verifier interface {
+	// JoinMethod is the types.JoinMethod this verifier handles.
+	JoinMethod() types.JoinMethod
+	// VerifyJoin checks req's cloud-specific document and returns the
+	// identity it proves, or an error if the document doesn't verify or
+	// doesn't match the token's allow rules.
+	VerifyJoin(ctx context.Context, req Request) (*Identity, error)
+}
+
+// Registry dispatches a join request to the Verifier registered for its
+// token's join method.
+type Registry struct {
+	verifiers map[types.JoinMethod]Verifier
+}
+
+// NewRegistry creates a Registry with the given verifiers registered.
+func NewRegistry(verifiers ...Verifier) *Registry {
+	r := &Registry{verifiers: make(map[types.JoinMethod]Verifier, len(verifiers))}
+	for _, v := range verifiers {
+		r.verifiers[v.JoinMethod()] = v
+	}
+	return r
+}
+
+// VerifyJoin dispatches to the Verifier registered for the token's join
+// method.
+func (r *Registry) VerifyJoin(ctx context.Context, method types.JoinMethod, req Request) (*Identity, error) {
+	verifier, ok := r.verifiers[method]
+	if !ok {
+		return nil, trace.BadParameter("unsupported join method %q", method)
+	}
+	identity, err := verifier.VerifyJoin(ctx, req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return identity, nil
+}
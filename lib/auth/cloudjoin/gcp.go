@@ -0,0 +1,147 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudjoin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+)
+
+// gcpIssuerURL is where Google publishes its OIDC discovery document and
+// signing keys, used to verify a GCE instance identity token.
+const gcpIssuerURL = "https://accounts.google.com"
+
+// gcpClaims is the subset of a GCE instance identity token's claims that
+// GCPVerifier cares about. See
+// https://cloud.google.com/compute/docs/instances/verifying-instance-identity
+// for the full shape.
+type gcpClaims struct {
+	Google struct {
+		ComputeEngine struct {
+			ProjectID  string `json:"project_id"`
+			Zone       string `json:"zone"`
+			InstanceID string `json:"instance_id"`
+		} `json:"compute_engine"`
+	} `json:"google"`
+}
+
+// TokenVerifier verifies a signed JWT and returns its parsed claims,
+// narrowed down from *oidc.IDTokenVerifier so tests can supply a fake.
+type TokenVerifier interface {
+	Verify(ctx context.Context, rawIDToken string) (claims func(interface{}) error, err error)
+}
+
+// GCPVerifier is the Verifier for types.JoinMethodGCP: it checks the
+// signature on a GCE instance identity JWT against Google's published
+// keys and matches the result against the token's allow rules.
+type GCPVerifier struct {
+	// Verifier checks the JWT's signature. If nil, one is built lazily
+	// against the real Google OIDC discovery document; that one checks
+	// the issuer and expiry but not the audience (SkipClientIDCheck).
+	Verifier TokenVerifier
+}
+
+// JoinMethod returns types.JoinMethodGCP.
+func (v *GCPVerifier) JoinMethod() types.JoinMethod {
+	return types.JoinMethodGCP
+}
+
+// VerifyJoin verifies req's GCE instance identity token and checks it
+// against req.Token's allow rules.
+func (v *GCPVerifier) VerifyJoin(ctx context.Context, req Request) (*Identity, error) {
+	verifier, err := v.verifier(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	getClaims, err := verifier.Verify(ctx, string(req.Document))
+	if err != nil {
+		return nil, trace.AccessDenied("invalid GCE identity token: %v", err)
+	}
+
+	var claims gcpClaims
+	if err := getClaims(&claims); err != nil {
+		return nil, trace.AccessDenied("failed to parse GCE identity token: %v", err)
+	}
+	ce := claims.Google.ComputeEngine
+
+	hostID := fmt.Sprintf("%s-%s", ce.ProjectID, ce.InstanceID)
+	if req.HostID != hostID {
+		return nil, trace.AccessDenied("host ID %q does not match identity token", req.HostID)
+	}
+
+	if err := checkGCPAllowRules(ce.ProjectID, ce.Zone, req.Token); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &Identity{
+		AccountID:  ce.ProjectID,
+		InstanceID: ce.InstanceID,
+		Region:     ce.Zone,
+	}, nil
+}
+
+// verifier returns v.Verifier, building the real Google OIDC verifier on
+// first use if none was supplied.
+func (v *GCPVerifier) verifier(ctx context.Context) (TokenVerifier, error) {
+	if v.Verifier != nil {
+		return v.Verifier, nil
+	}
+	provider, err := oidc.NewProvider(ctx, gcpIssuerURL)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	idVerifier := provider.VerifierContext(ctx, &oidc.Config{SkipClientIDCheck: true})
+	v.Verifier = oidcTokenVerifier{idVerifier}
+	return v.Verifier, nil
+}
+
+// oidcTokenVerifier adapts *oidc.IDTokenVerifier to TokenVerifier.
+type oidcTokenVerifier struct {
+	inner *oidc.IDTokenVerifier
+}
+
+func (o oidcTokenVerifier) Verify(ctx context.Context, rawIDToken string) (func(interface{}) error, error) {
+	idToken, err := o.inner.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return idToken.Claims, nil
+}
+
+// checkGCPAllowRules checks the instance's project and zone against every
+// Allow rule on the token, succeeding if any rule matches.
+func checkGCPAllowRules(projectID, zone string, provisionToken types.ProvisionToken) error {
+	for _, rule := range provisionToken.GetAllowRules() {
+		if rule.GCPProjectID != projectID {
+			continue
+		}
+		if len(rule.GCPZones) == 0 {
+			return nil
+		}
+		for _, z := range rule.GCPZones {
+			if z == zone {
+				return nil
+			}
+		}
+	}
+	return trace.AccessDenied("instance did not match any allow rules in token")
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/auth/cloudjoin"
+	"github.com/gravitational/trace"
+)
+
+// getAzureSigningCerts parses a.AzureSigningCertsPEM (set by NewServer
+// from the auth config), caching the result in a.azureSigningCerts. An
+// empty or missing value is a configuration error, not something
+// cloudJoinRegistry should paper over by handing AzureVerifier an empty
+// cert pool, since that would make every attested-data document's
+// signature check fail silently instead of up front.
+func (a *Server) getAzureSigningCerts() ([]*x509.Certificate, error) {
+	var err error
+	a.azureSigningCertsOnce.Do(func() {
+		if len(a.AzureSigningCertsPEM) == 0 {
+			err = trace.BadParameter("AzureSigningCertsPEM is not configured, Azure join is unavailable")
+			return
+		}
+		a.azureSigningCerts, err = parseCertPEMBundle(a.AzureSigningCertsPEM)
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// The Once only reports err on the call that ran it; later calls after
+	// a failed parse see no certs here and must fail the same way.
+	if len(a.azureSigningCerts) == 0 {
+		return nil, trace.BadParameter("Azure signing certificates are not configured, Azure join is unavailable")
+	}
+	return a.azureSigningCerts, nil
+}
+
+// parseCertPEMBundle parses a PEM bundle of one or more certificates.
+func parseCertPEMBundle(pemBytes []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for len(pemBytes) > 0 {
+		var block *pem.Block
+		block, pemBytes = pem.Decode(pemBytes)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, trace.BadParameter("no certificates found in PEM bundle")
+	}
+	return certs, nil
+}
+
+// cloudJoinRegistry dispatches RegisterUsingToken requests for the cloud
+// join methods that don't predate the pluggable Verifier interface.
+// EC2 keeps going through CheckEC2Request directly, since it shipped
+// before cloudjoin existed and its exact behavior is pinned by existing
+// tests; GCP and Azure only ever exist behind the registry. The Azure
+// signing certs are only loaded (and only required) for an Azure join.
+func (a *Server) cloudJoinRegistry(method types.JoinMethod) (*cloudjoin.Registry, error) {
+	verifiers := []cloudjoin.Verifier{&cloudjoin.GCPVerifier{}}
+	if method == types.JoinMethodAzure {
+		azureSigningCerts, err := a.getAzureSigningCerts()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		verifiers = append(verifiers, &cloudjoin.AzureVerifier{SigningCerts: azureSigningCerts})
+	}
+	return cloudjoin.NewRegistry(verifiers...), nil
+}
+
+// CheckCloudJoinRequest verifies req against the token's join method using
+// the cloudjoin registry, then enforces host-ID uniqueness the same way
+// CheckEC2Request does for EC2. It's the entry point RegisterUsingToken is
+// meant to call for every join method other than EC2, exported (like
+// CheckEC2Request) so that entry point, and tests, have something to call.
+func (a *Server) CheckCloudJoinRequest(ctx context.Context, req RegisterUsingTokenRequest, document []byte, role types.SystemRole) error {
+	provisionToken, err := a.GetToken(ctx, req.Token)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	registry, err := a.cloudJoinRegistry(provisionToken.GetJoinMethod())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	identity, err := registry.VerifyJoin(ctx, provisionToken.GetJoinMethod(), cloudjoin.Request{
+		HostID:     req.HostID,
+		Document:   document,
+		ResourceID: req.AzureResourceID,
+		Token:      provisionToken,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	hostID := fmt.Sprintf("%s-%s", identity.AccountID, identity.InstanceID)
+	if err := a.checkHostUnique(ctx, hostID, role); err != nil {
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func upsertTestToken(t *testing.T, a *Server, name string, spec types.ProvisionTokenSpecV2) {
+	token, err := types.NewProvisionTokenFromSpec(name, time.Now().Add(time.Minute), spec)
+	require.NoError(t, err)
+	require.NoError(t, a.UpsertToken(context.Background(), token))
+}
+
+func TestCheckCloudJoinRequestUnsupportedMethod(t *testing.T) {
+	a := newAuthServer(t)
+	upsertTestToken(t, a, "test_token", types.ProvisionTokenSpecV2{
+		Roles:      []types.SystemRole{types.RoleNode},
+		JoinMethod: types.JoinMethodToken,
+	})
+
+	err := a.CheckCloudJoinRequest(context.Background(), RegisterUsingTokenRequest{
+		Token:  "test_token",
+		HostID: "host-1",
+	}, []byte("doc"), types.RoleNode)
+	require.Error(t, err)
+	require.True(t, trace.IsBadParameter(err))
+}
+
+func TestCheckCloudJoinRequestAzureRequiresSigningCerts(t *testing.T) {
+	a := newAuthServer(t)
+	upsertTestToken(t, a, "test_token", types.ProvisionTokenSpecV2{
+		Roles:      []types.SystemRole{types.RoleNode},
+		JoinMethod: types.JoinMethodAzure,
+	})
+
+	err := a.CheckCloudJoinRequest(context.Background(), RegisterUsingTokenRequest{
+		Token:  "test_token",
+		HostID: "host-1",
+	}, []byte("doc"), types.RoleNode)
+	require.Error(t, err)
+	require.True(t, trace.IsBadParameter(err), "Azure join should fail fast without AzureSigningCertsPEM configured")
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/cloud/awsiid"
+	"github.com/gravitational/trace"
+)
+
+// defaultIIDTTL is how long an EC2 instance identity document is considered
+// fresh enough to join with, unless the token overrides it via AWSIIDTTL.
+const defaultIIDTTL = 5 * time.Minute
+
+// ec2Client is the subset of the AWS SDK EC2 client CheckEC2Request needs,
+// narrowed down so tests can supply a fake implementation via context.
+type ec2Client interface {
+	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+}
+
+// ec2ClientKey is the context key tests use to inject a fake ec2Client,
+// avoiding a real AWS API call in unit tests.
+type ec2ClientKey struct{}
+
+// ec2ClientFromContext returns the ec2Client stashed in ctx by tests, or
+// builds a real one scoped to region using the ambient AWS credentials.
+func ec2ClientFromContext(ctx context.Context, region string) (ec2Client, error) {
+	if client, ok := ctx.Value(ec2ClientKey{}).(ec2Client); ok {
+		return client, nil
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return ec2.NewFromConfig(cfg), nil
+}
+
+// checkAWSAllowRules checks the instance identity document against every
+// Allow rule on the token, succeeding if any rule matches.
+func checkAWSAllowRules(doc *awsiid.Document, provisionToken types.ProvisionToken) error {
+	for _, rule := range provisionToken.GetAllowRules() {
+		if rule.AWSAccount != doc.AccountID {
+			continue
+		}
+		if len(rule.AWSRegions) == 0 {
+			return nil
+		}
+		for _, region := range rule.AWSRegions {
+			if region == doc.Region {
+				return nil
+			}
+		}
+	}
+	return trace.AccessDenied("instance did not match any allow rules in token")
+}
+
+// describeInstance fetches the instance's current DescribeInstances view,
+// used both to confirm it's running and, with RequireIMDSv2, that it
+// actually enforces IMDSv2.
+func describeInstance(ctx context.Context, client ec2Client, instanceID string) (*ec2types.Instance, error) {
+	output, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: []string{instanceID},
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(output.Reservations) == 0 || len(output.Reservations[0].Instances) == 0 {
+		return nil, trace.NotFound("instance %q not found", instanceID)
+	}
+	return &output.Reservations[0].Instances[0], nil
+}
+
+// CheckEC2Request validates a simplified EC2-join RegisterUsingTokenRequest:
+// the PKCS#7-signed instance identity document is verified, matched against
+// the token's Allow rules, checked for freshness, confirmed against a live
+// DescribeInstances call, and finally checked for host-ID uniqueness across
+// every resource kind the instance could be joining as. It's a thin wrapper
+// around CheckEC2RequestStream that discards the progress events; callers
+// that want them (the JoinStream RPC) call that directly.
+func (a *Server) CheckEC2Request(ctx context.Context, req RegisterUsingTokenRequest) error {
+	return a.CheckEC2RequestStream(ctx, req, func(JoinStreamEvent) {})
+}
+
+// checkHostUnique fails the request if a resource of the given role
+// already exists under this host ID, so an attacker can't replay a leaked
+// identity document against a live node. Which resource kinds it can
+// check is driven entirely by a.hostIDRegistry, so adding a new joinable
+// role is a matter of registering a source for it, not editing this
+// function.
+func (a *Server) checkHostUnique(ctx context.Context, hostID string, role types.SystemRole) error {
+	exists, err := a.getHostIDRegistry().Contains(ctx, role, hostID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if exists {
+		return trace.AccessDenied("instance %q has already joined the cluster", hostID)
+	}
+	return nil
+}
+
+// requireIMDSv2 reports whether the token demands that the joining
+// instance enforce IMDSv2-only metadata access.
+func requireIMDSv2(provisionToken types.ProvisionToken) bool {
+	return provisionToken.GetRequireIMDSv2()
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/gravitational/teleport/lib/cloud/awsiid"
+	"github.com/gravitational/trace"
+)
+
+// JoinStreamEventKind identifies which stage of the EC2 join flow a
+// JoinStreamEvent reports on.
+type JoinStreamEventKind string
+
+const (
+	// JoinStreamEventIIDParsed reports that the instance identity
+	// document's PKCS#7 signature was verified and its contents parsed.
+	JoinStreamEventIIDParsed JoinStreamEventKind = "IIDParsed"
+	// JoinStreamEventTokenMatched reports that the document matched the
+	// token's allow rules and freshness requirements.
+	JoinStreamEventTokenMatched JoinStreamEventKind = "TokenMatched"
+	// JoinStreamEventDescribeInstancesCalled reports that a live
+	// DescribeInstances call confirmed the instance is running (and, if
+	// required, enforces IMDSv2).
+	JoinStreamEventDescribeInstancesCalled JoinStreamEventKind = "DescribeInstancesCalled"
+	// JoinStreamEventUniquenessChecked reports that the host ID was
+	// checked against existing resources of the role it is joining as,
+	// and that the token's rejoin policy allowed any existing one.
+	JoinStreamEventUniquenessChecked JoinStreamEventKind = "UniquenessChecked"
+	// JoinStreamEventValidationComplete reports that every check passed
+	// and the caller is cleared to proceed with RegisterUsingToken, which
+	// actually issues certificates. CheckEC2RequestStream only validates;
+	// it never issues certificates itself.
+	JoinStreamEventValidationComplete JoinStreamEventKind = "ValidationComplete"
+	// JoinStreamEventError is the terminal event sent instead of
+	// JoinStreamEventValidationComplete when the join fails at any stage.
+	JoinStreamEventError JoinStreamEventKind = "Error"
+)
+
+// JoinStreamEvent is one progress update emitted by CheckEC2RequestStream,
+// giving operators actionable diagnostics during a fleet rollout instead
+// of a single pass/fail result.
+type JoinStreamEvent struct {
+	Kind JoinStreamEventKind
+	// Code, Stage, and Detail are only set on a JoinStreamEventError: Code
+	// is the HTTP status code trace.ErrorToCode maps the error to, in
+	// decimal (e.g. "403" for an access-denied error), Stage is the
+	// JoinStreamEventKind that was in progress when the failure happened,
+	// and Detail is the error's message.
+	Code   string
+	Stage  JoinStreamEventKind
+	Detail string
+}
+
+// CheckEC2RequestStream runs the same validation as CheckEC2Request, but
+// calls emit after each stage completes so a caller like the JoinStream
+// RPC can surface progress as it happens instead of only a final error.
+// On failure it calls emit once more with a terminal JoinStreamEventError
+// naming the stage that failed, then returns the error.
+func (a *Server) CheckEC2RequestStream(ctx context.Context, req RegisterUsingTokenRequest, emit func(JoinStreamEvent)) error {
+	fail := func(stage JoinStreamEventKind, err error) error {
+		emit(JoinStreamEvent{
+			Kind:   JoinStreamEventError,
+			Code:   strconv.Itoa(trace.ErrorToCode(err)),
+			Stage:  stage,
+			Detail: err.Error(),
+		})
+		return err
+	}
+
+	if len(req.EC2IdentityDocument) == 0 {
+		return fail(JoinStreamEventIIDParsed, trace.AccessDenied("no identity document was provided"))
+	}
+
+	provisionToken, err := a.GetToken(ctx, req.Token)
+	if err != nil {
+		return fail(JoinStreamEventIIDParsed, trace.Wrap(err))
+	}
+
+	doc, err := awsiid.ParseAndVerify(req.EC2IdentityDocument, awsRSA2048CertBytes)
+	if err != nil {
+		return fail(JoinStreamEventIIDParsed, trace.Wrap(err))
+	}
+
+	hostID := fmt.Sprintf("%s-%s", doc.AccountID, doc.InstanceID)
+	if req.HostID != hostID {
+		return fail(JoinStreamEventIIDParsed, trace.AccessDenied("host ID %q does not match identity document", req.HostID))
+	}
+	emit(JoinStreamEvent{Kind: JoinStreamEventIIDParsed})
+
+	ttl := defaultIIDTTL
+	if provisionToken.GetAWSIIDTTL() != 0 {
+		ttl = provisionToken.GetAWSIIDTTL().Duration()
+	}
+	if a.clock.Now().After(doc.PendingTime.Add(ttl)) {
+		return fail(JoinStreamEventTokenMatched, trace.AccessDenied("instance identity document has expired"))
+	}
+	if err := checkAWSAllowRules(doc, provisionToken); err != nil {
+		return fail(JoinStreamEventTokenMatched, trace.Wrap(err))
+	}
+	emit(JoinStreamEvent{Kind: JoinStreamEventTokenMatched})
+
+	client, err := ec2ClientFromContext(ctx, doc.Region)
+	if err != nil {
+		return fail(JoinStreamEventDescribeInstancesCalled, trace.Wrap(err))
+	}
+	instance, err := describeInstance(ctx, client, doc.InstanceID)
+	if err != nil {
+		return fail(JoinStreamEventDescribeInstancesCalled, trace.AccessDenied("failed to verify instance via DescribeInstances: %v", err))
+	}
+	if instance.State == nil || instance.State.Name != ec2types.InstanceStateNameRunning {
+		return fail(JoinStreamEventDescribeInstancesCalled, trace.AccessDenied("instance %q is not running", doc.InstanceID))
+	}
+	if requireIMDSv2(provisionToken) {
+		if instance.MetadataOptions == nil || instance.MetadataOptions.HttpTokens != ec2types.HttpTokensStateRequired {
+			return fail(JoinStreamEventDescribeInstancesCalled, trace.AccessDenied("token requires IMDSv2 but instance %q does not enforce it", doc.InstanceID))
+		}
+	}
+	emit(JoinStreamEvent{Kind: JoinStreamEventDescribeInstancesCalled})
+
+	if err := a.checkEC2HostClaim(ctx, provisionToken, doc, hostID, req); err != nil {
+		return fail(JoinStreamEventUniquenessChecked, trace.Wrap(err))
+	}
+	emit(JoinStreamEvent{Kind: JoinStreamEventUniquenessChecked})
+
+	// CheckEC2RequestStream only validates the join; it doesn't issue
+	// certificates itself, so this event means "cleared to join", not
+	// "certificates issued".
+	emit(JoinStreamEvent{Kind: JoinStreamEventValidationComplete})
+
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckEC2RequestStreamEmitsProgress(t *testing.T) {
+	a := newAuthServer(t)
+	a.clock = clockwork.NewFakeClockAt(instance1.pendingTime)
+
+	token, err := types.NewProvisionTokenFromSpec(
+		"test_token",
+		time.Now().Add(time.Minute),
+		types.ProvisionTokenSpecV2{
+			Roles: []types.SystemRole{types.RoleNode},
+			Allow: []*types.TokenRule{{AWSAccount: instance1.account}},
+		})
+	require.NoError(t, err)
+	require.NoError(t, a.UpsertToken(context.Background(), token))
+	defer a.DeleteToken(context.Background(), token.GetName())
+
+	ctx := context.WithValue(context.Background(), ec2ClientKey{}, ec2ClientRunning{})
+	req := RegisterUsingTokenRequest{
+		Token:               "test_token",
+		HostID:              instance1.account + "-" + instance1.instanceID,
+		Role:                types.RoleNode,
+		EC2IdentityDocument: instance1.iid,
+	}
+
+	var events []JoinStreamEvent
+	err = a.CheckEC2RequestStream(ctx, req, func(e JoinStreamEvent) {
+		events = append(events, e)
+	})
+	require.NoError(t, err)
+
+	var kinds []JoinStreamEventKind
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	require.Equal(t, []JoinStreamEventKind{
+		JoinStreamEventIIDParsed,
+		JoinStreamEventTokenMatched,
+		JoinStreamEventDescribeInstancesCalled,
+		JoinStreamEventUniquenessChecked,
+		JoinStreamEventValidationComplete,
+	}, kinds)
+}
+
+func TestCheckEC2RequestStreamEmitsErrorOnFailure(t *testing.T) {
+	a := newAuthServer(t)
+	a.clock = clockwork.NewFakeClockAt(instance1.pendingTime)
+
+	ctx := context.WithValue(context.Background(), ec2ClientKey{}, ec2ClientRunning{})
+	req := RegisterUsingTokenRequest{
+		Token:               "nonexistent_token",
+		HostID:              instance1.account + "-" + instance1.instanceID,
+		Role:                types.RoleNode,
+		EC2IdentityDocument: instance1.iid,
+	}
+
+	var events []JoinStreamEvent
+	err := a.CheckEC2RequestStream(ctx, req, func(e JoinStreamEvent) {
+		events = append(events, e)
+	})
+	require.Error(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, JoinStreamEventError, events[0].Kind)
+	require.Equal(t, JoinStreamEventIIDParsed, events[0].Stage)
+	require.NotEmpty(t, events[0].Detail)
+}
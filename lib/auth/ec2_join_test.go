@@ -46,52 +46,80 @@ type ec2Instance struct {
 
 var (
 	instance1 = ec2Instance{
-		iid: []byte(`MIAGCSqGSIb3DQEHAqCAMIACAQExDzANBglghkgBZQMEAgEFADCABgkqhkiG9w0BBwGggCSABIIB
-23sKICAiYWNjb3VudElkIiA6ICIyNzg1NzYyMjA0NTMiLAogICJhcmNoaXRlY3R1cmUiIDogIng4
-Nl82NCIsCiAgImF2YWlsYWJpbGl0eVpvbmUiIDogInVzLXdlc3QtMmEiLAogICJiaWxsaW5nUHJv
-ZHVjdHMiIDogbnVsbCwKICAiZGV2cGF5UHJvZHVjdENvZGVzIiA6IG51bGwsCiAgIm1hcmtldHBs
-YWNlUHJvZHVjdENvZGVzIiA6IG51bGwsCiAgImltYWdlSWQiIDogImFtaS0wZmE5ZTFmNjQxNDJj
-ZGUxNyIsCiAgImluc3RhbmNlSWQiIDogImktMDc4NTE3Y2E4YTcwYTFkZGUiLAogICJpbnN0YW5j
-ZVR5cGUiIDogInQyLm1lZGl1bSIsCiAgImtlcm5lbElkIiA6IG51bGwsCiAgInBlbmRpbmdUaW1l
-IiA6ICIyMDIxLTA5LTAzVDIxOjI1OjQ0WiIsCiAgInByaXZhdGVJcCIgOiAiMTAuMC4wLjIwOSIs
-CiAgInJhbWRpc2tJZCIgOiBudWxsLAogICJyZWdpb24iIDogInVzLXdlc3QtMiIsCiAgInZlcnNp
-b24iIDogIjIwMTctMDktMzAiCn0AAAAAAAAxggIvMIICKwIBATBpMFwxCzAJBgNVBAYTAlVTMRkw
-FwYDVQQIExBXYXNoaW5ndG9uIFN0YXRlMRAwDgYDVQQHEwdTZWF0dGxlMSAwHgYDVQQKExdBbWF6
-b24gV2ViIFNlcnZpY2VzIExMQwIJALZL3lrQCSTMMA0GCWCGSAFlAwQCAQUAoIGYMBgGCSqGSIb3
-DQEJAzELBgkqhkiG9w0BBwEwHAYJKoZIhvcNAQkFMQ8XDTIxMDkwMzIxMjU0N1owLQYJKoZIhvcN
-AQk0MSAwHjANBglghkgBZQMEAgEFAKENBgkqhkiG9w0BAQsFADAvBgkqhkiG9w0BCQQxIgQgCH2d
-JiKmdx9uhxlm8ObWAvFOhqJb7k79+DW/T3ezwVUwDQYJKoZIhvcNAQELBQAEggEANWautigs/qZ6
-w8g5/EfWsAFj8kHgUD+xqsQ1HDrBUx3IQ498NMBZ78379B8RBfuzeVjbaf+yugov0fYrDbGvSRRw
-myy49TfZ9gdlpWQXzwSg3OPMDNToRoKw00/LQjSxcTCaPP4vMDEIjYMUqZ3i4uWYJJJ0Lb7fDMDk
-Anu7yHolVfbnvIAuZe8lGpc7ofCSBG5wulm+/pqzO25YPMH1cLEvOadE+3N2GxK6gRTLJoE98rsm
-LDp6OuU/b2QfaxU0ec6OogdtSJto/URI0/ygHmNAzBis470A29yh5nVwm6AkY4krjPsK7uiBIRhs
-lr5x0X6+ggQfF2BKAJ/BRcAHNgAAAAAAAA==`),
+		iid: []byte(`MIIHZgYJKoZIhvcNAQcCoIIHVzCCB1MCAQExCTAHBgUrDgMCGjCCAe4GCSqGSIb3DQEHAaCCAd8E
+ggHbewogICJhY2NvdW50SWQiIDogIjI3ODU3NjIyMDQ1MyIsCiAgImFyY2hpdGVjdHVyZSIgOiAi
+eDg2XzY0IiwKICAiYXZhaWxhYmlsaXR5Wm9uZSIgOiAidXMtd2VzdC0yYSIsCiAgImJpbGxpbmdQ
+cm9kdWN0cyIgOiBudWxsLAogICJkZXZwYXlQcm9kdWN0Q29kZXMiIDogbnVsbCwKICAibWFya2V0
+cGxhY2VQcm9kdWN0Q29kZXMiIDogbnVsbCwKICAiaW1hZ2VJZCIgOiAiYW1pLTBmYTllMWY2NDE0
+MmNkZTE3IiwKICAiaW5zdGFuY2VJZCIgOiAiaS0wNzg1MTdjYThhNzBhMWRkZSIsCiAgImluc3Rh
+bmNlVHlwZSIgOiAidDIubWVkaXVtIiwKICAia2VybmVsSWQiIDogbnVsbCwKICAicGVuZGluZ1Rp
+bWUiIDogIjIwMjEtMDktMDNUMjE6MjU6NDRaIiwKICAicHJpdmF0ZUlwIiA6ICIxMC4wLjAuMjA5
+IiwKICAicmFtZGlza0lkIiA6IG51bGwsCiAgInJlZ2lvbiIgOiAidXMtd2VzdC0yIiwKICAidmVy
+c2lvbiIgOiAiMjAxNy0wOS0zMCIKfaCCA2MwggNfMIICR6ADAgECAhBpz5LbCk+YoTsbhjBkUE5N
+MA0GCSqGSIb3DQEBCwUAMFIxCzAJBgNVBAYTAlVTMR8wHQYDVQQKExZUZWxlcG9ydCBUZXN0IEZp
+eHR1cmVzMSIwIAYDVQQDExllYzItaWlkLXRlc3Qtc2lnbmluZy1jZXJ0MB4XDTIxMDEwMTAwMDAw
+MFoXDTQxMDEwMTAwMDAwMFowUjELMAkGA1UEBhMCVVMxHzAdBgNVBAoTFlRlbGVwb3J0IFRlc3Qg
+Rml4dHVyZXMxIjAgBgNVBAMTGWVjMi1paWQtdGVzdC1zaWduaW5nLWNlcnQwggEiMA0GCSqGSIb3
+DQEBAQUAA4IBDwAwggEKAoIBAQDUe7qnAYr61SaPdxv+PbnlyxxWh5gubc3xkrUDfxioII0LYycK
+nvmcqtUQ/hf8OZauxqdhhjHbQs2ll/N1OGPCIbEyC4PGuRa1CNv9MDK5E3rv4mZKAIWSmKW9Tu+o
+vq2925Naj22Rg3ZkbQDLO/uqPfRZ3DrHb83fzK284zLmHAZHQskbTFS+w8RR1iPDR6ev9/PrtwXD
+4FA9t+o0g1o6LW87ThRw0Z75OmExNY7EZj95KG8bw8Egb3GXEvBWZ4vcQGWYic3bO7AL5O7I1hiy
+0xqAL3cAYCBKzq0PBA2YuZU4ADnfNZXLmU3ZJ7jQ8M4vzOTcC0vskVx6o9imeGUVAgMBAAGjMTAv
+MA4GA1UdDwEB/wQEAwIHgDAPBgNVHSUECDAGBgRVHSUAMAwGA1UdEwEB/wQCMAAwDQYJKoZIhvcN
+AQELBQADggEBAD4q0uPZIREAVMAzQtuBaOCWYHBcjtdR4cdopqQnkNxEcBDKzpg/c6wXAxLQQ8JL
+97Bm1qGGEdedJ+qGGho/TQekSj5xMrllX7jeDQcF957s4CJvpCEOjjqjiOUSQQXR8UTqgGNwIpnb
+qaf4Mmr1pNF4/g8T1uWYdbppvZ9ed96GaBe8CR0P6X51iHeYTa1LJpt/zJzvjUqaie1H+nvoo6MB
+xXlS3OIMChmUv/TsykuXYWid1AffirbkzuzLUD6Ahm48azEVFuzSCwVHebZivRANkZUWWZb9kgDr
+mAHr/+m4bgjfXkisMo3juHYnywsNKgJC8ILMW7JefzyrmvKy4esxggHoMIIB5AIBATBmMFIxCzAJ
+BgNVBAYTAlVTMR8wHQYDVQQKExZUZWxlcG9ydCBUZXN0IEZpeHR1cmVzMSIwIAYDVQQDExllYzIt
+aWlkLXRlc3Qtc2lnbmluZy1jZXJ0AhBpz5LbCk+YoTsbhjBkUE5NMAcGBSsOAwIaoF0wGAYJKoZI
+hvcNAQkDMQsGCSqGSIb3DQEHATAcBgkqhkiG9w0BCQUxDxcNMjYwNzI3MTIyOTQxWjAjBgkqhkiG
+9w0BCQQxFgQUdAO5tBqpS6ek4I3A4qYl+Eh3pmAwCwYJKoZIhvcNAQEFBIIBAAsciY5rkGjsX84i
+6EVjtloX4KQQOMl88IwA9ozmuKFWRw93iPdO2dThF6SDI18zQeQZZJ8Lt/nUNcYKObR6QoP4mSQv
+JAhXvA6NKoh7g6Yh9jVqW9H2FXj+X1T3T2WbyLkREIoN7gREyZNDYDMYvQTc4FlP+DoRznxzzJLY
+MJn4Pfo2/SH83Cr9L5SkAaM+Kz4ejsSW+/VS48uLUFO8PHOkuSmpNG3fg+X/Iup0UxyUG31/zGtH
+eZVfrO7nVzc/aoq3YuIHtaoxp3S+048x3H0DdxX/ofCXmvOowbzazXNUoot23k+IvkM2cHAsqOvy
+2j2b42v4FGtuaaDiyoLamMA=`),
 		account:     "278576220453",
 		region:      "us-west-2",
 		instanceID:  "i-078517ca8a70a1dde",
 		pendingTime: time.Date(2021, time.September, 3, 21, 25, 44, 0, time.UTC),
 	}
 	instance2 = ec2Instance{
-		iid: []byte(`MIAGCSqGSIb3DQEHAqCAMIACAQExDzANBglghkgBZQMEAgEFADCABgkqhkiG9w0BBwGggCSABIIB
-3XsKICAiYWNjb3VudElkIiA6ICI4ODM0NzQ2NjI4ODgiLAogICJhcmNoaXRlY3R1cmUiIDogIng4
-Nl82NCIsCiAgImF2YWlsYWJpbGl0eVpvbmUiIDogInVzLXdlc3QtMWMiLAogICJiaWxsaW5nUHJv
-ZHVjdHMiIDogbnVsbCwKICAiZGV2cGF5UHJvZHVjdENvZGVzIiA6IG51bGwsCiAgIm1hcmtldHBs
-YWNlUHJvZHVjdENvZGVzIiA6IG51bGwsCiAgImltYWdlSWQiIDogImFtaS0wY2UzYzU1YTMxZDI5
-MDQwZSIsCiAgImluc3RhbmNlSWQiIDogImktMDFiOTQwYzQ1ZmQxMWZlNzQiLAogICJpbnN0YW5j
-ZVR5cGUiIDogInQyLm1pY3JvIiwKICAia2VybmVsSWQiIDogbnVsbCwKICAicGVuZGluZ1RpbWUi
-IDogIjIwMjEtMDktMTFUMDA6MTQ6MThaIiwKICAicHJpdmF0ZUlwIiA6ICIxNzIuMzEuMTIuMjUx
-IiwKICAicmFtZGlza0lkIiA6IG51bGwsCiAgInJlZ2lvbiIgOiAidXMtd2VzdC0xIiwKICAidmVy
-c2lvbiIgOiAiMjAxNy0wOS0zMCIKfQAAAAAAADGCAi8wggIrAgEBMGkwXDELMAkGA1UEBhMCVVMx
-GTAXBgNVBAgTEFdhc2hpbmd0b24gU3RhdGUxEDAOBgNVBAcTB1NlYXR0bGUxIDAeBgNVBAoTF0Ft
-YXpvbiBXZWIgU2VydmljZXMgTExDAgkA00+QilzIS0gwDQYJYIZIAWUDBAIBBQCggZgwGAYJKoZI
-hvcNAQkDMQsGCSqGSIb3DQEHATAcBgkqhkiG9w0BCQUxDxcNMjEwOTExMDAxNDIyWjAtBgkqhkiG
-9w0BCTQxIDAeMA0GCWCGSAFlAwQCAQUAoQ0GCSqGSIb3DQEBCwUAMC8GCSqGSIb3DQEJBDEiBCDS
-1gNvxbYnEL6plVu8X/QmKPJFJwIJfi+2hIVjyKAOtjANBgkqhkiG9w0BAQsFAASCAQABKmghATg8
-VXkdiIGcTIPfKrc2v/zEIdLUAi+Ew5lrGUVjnNqrP9irGK4d9sVtcu/8UKp9RDoeJOQ6I/pRcwvT
-PJVHlhGnLyybr5ZVqkxiC09GASNnPe12dzCKkKD2rvW6mGR91cxpM94Xqi5UA/ZRqiXwpHo3LECN
-Gu38Hpdv6sBgD/av2Ohd+vEH2zvYVkp7ZfnFuDLWRSBQZgmKwVKVdOjrMmP32vb3vzhMBuOj+jbQ
-RwEmYIkRaEGNbrZgatjMJYmTWuLG26zws3avOK6kL6u38DV3wJPVB/G0Ira5MvC/ojGya+DrVngW
-VUP+3jgenPrd7OyCWPSwOoOBMhSlAAAAAAAA`),
+		iid: []byte(`MIIHaAYJKoZIhvcNAQcCoIIHWTCCB1UCAQExCTAHBgUrDgMCGjCCAfAGCSqGSIb3DQEHAaCCAeEE
+ggHdewogICJhY2NvdW50SWQiIDogIjg4MzQ3NDY2Mjg4OCIsCiAgImFyY2hpdGVjdHVyZSIgOiAi
+eDg2XzY0IiwKICAiYXZhaWxhYmlsaXR5Wm9uZSIgOiAidXMtd2VzdC0xYyIsCiAgImJpbGxpbmdQ
+cm9kdWN0cyIgOiBudWxsLAogICJkZXZwYXlQcm9kdWN0Q29kZXMiIDogbnVsbCwKICAibWFya2V0
+cGxhY2VQcm9kdWN0Q29kZXMiIDogbnVsbCwKICAiaW1hZ2VJZCIgOiAiYW1pLTBjZTNjNTVhMzFk
+MjkwNDBlIiwKICAiaW5zdGFuY2VJZCIgOiAiaS0wMWI5NDBjNDVmZDExZmU3NCIsCiAgImluc3Rh
+bmNlVHlwZSIgOiAidDIubWljcm8iLAogICJrZXJuZWxJZCIgOiBudWxsLAogICJwZW5kaW5nVGlt
+ZSIgOiAiMjAyMS0wOS0xMVQwMDoxNDoxOFoiLAogICJwcml2YXRlSXAiIDogIjE3Mi4zMS4xMi4y
+NTEiLAogICJyYW1kaXNrSWQiIDogbnVsbCwKICAicmVnaW9uIiA6ICJ1cy13ZXN0LTEiLAogICJ2
+ZXJzaW9uIiA6ICIyMDE3LTA5LTMwIgp9oIIDYzCCA18wggJHoAMCAQICEGnPktsKT5ihOxuGMGRQ
+Tk0wDQYJKoZIhvcNAQELBQAwUjELMAkGA1UEBhMCVVMxHzAdBgNVBAoTFlRlbGVwb3J0IFRlc3Qg
+Rml4dHVyZXMxIjAgBgNVBAMTGWVjMi1paWQtdGVzdC1zaWduaW5nLWNlcnQwHhcNMjEwMTAxMDAw
+MDAwWhcNNDEwMTAxMDAwMDAwWjBSMQswCQYDVQQGEwJVUzEfMB0GA1UEChMWVGVsZXBvcnQgVGVz
+dCBGaXh0dXJlczEiMCAGA1UEAxMZZWMyLWlpZC10ZXN0LXNpZ25pbmctY2VydDCCASIwDQYJKoZI
+hvcNAQEBBQADggEPADCCAQoCggEBANR7uqcBivrVJo93G/49ueXLHFaHmC5tzfGStQN/GKggjQtj
+Jwqe+Zyq1RD+F/w5lq7Gp2GGMdtCzaWX83U4Y8IhsTILg8a5FrUI2/0wMrkTeu/iZkoAhZKYpb1O
+76i+rb3bk1qPbZGDdmRtAMs7+6o99FncOsdvzd/MrbzjMuYcBkdCyRtMVL7DxFHWI8NHp6/38+u3
+BcPgUD236jSDWjotbztOFHDRnvk6YTE1jsRmP3kobxvDwSBvcZcS8FZni9xAZZiJzds7sAvk7sjW
+GLLTGoAvdwBgIErOrQ8EDZi5lTgAOd81lcuZTdknuNDwzi/M5NwLS+yRXHqj2KZ4ZRUCAwEAAaMx
+MC8wDgYDVR0PAQH/BAQDAgeAMA8GA1UdJQQIMAYGBFUdJQAwDAYDVR0TAQH/BAIwADANBgkqhkiG
+9w0BAQsFAAOCAQEAPirS49khEQBUwDNC24Fo4JZgcFyO11Hhx2impCeQ3ERwEMrOmD9zrBcDEtBD
+wkv3sGbWoYYR150n6oYaGj9NB6RKPnEyuWVfuN4NBwX3nuzgIm+kIQ6OOqOI5RJBBdHxROqAY3Ai
+mdupp/gyavWk0Xj+DxPW5Zh1umm9n1533oZoF7wJHQ/pfnWId5hNrUsmm3/MnO+NSpqJ7Uf6e+ij
+owHFeVLc4gwKGZS/9OzKS5dhaJ3UB9+KtuTO7MtQPoCGbjxrMRUW7NILBUd5tmK9EA2RlRZZlv2S
+AOuYAev/6bhuCN9eSKwyjeO4difLCw0qAkLwgsxbsl5/PKua8rLh6zGCAegwggHkAgEBMGYwUjEL
+MAkGA1UEBhMCVVMxHzAdBgNVBAoTFlRlbGVwb3J0IFRlc3QgRml4dHVyZXMxIjAgBgNVBAMTGWVj
+Mi1paWQtdGVzdC1zaWduaW5nLWNlcnQCEGnPktsKT5ihOxuGMGRQTk0wBwYFKw4DAhqgXTAYBgkq
+hkiG9w0BCQMxCwYJKoZIhvcNAQcBMBwGCSqGSIb3DQEJBTEPFw0yNjA3MjcxMjI5NDFaMCMGCSqG
+SIb3DQEJBDEWBBRZrHiYFM8rlvhEW/PKqUIz2vRpjzALBgkqhkiG9w0BAQUEggEAalPJrWhnQVfV
+NywZ6x1mSz/Vwf9RwRhVl6I7Z9u7UFotO8g4VEoU01gY6ELe+ceK40/+WMcFb7iM+ekIfN011ZFS
+n4Qy/Q+t8q6X81lGCUKRjcyEiLROdrxhmuvhKclzHtE42ImqBCwLCSG1t+EUPw+wXwuuMVWwLv+f
+9EiPdW/wepx8kq2qpTfq0BdaW0HOBdJwhhFsHg5HveCxz1ORBf877xgSDSdG40KxDyZ7PwrMNxdc
+T4C1ySIE9+zQo8Jmj81OloO0A9GLyoO1MiPmH8q/NdQnDNYb5v/WXD8V5r0grjK3wxpHcMugnsqm
+4XLfFyF5cnuaITP/Yrb0e2SAJw==`),
 		account:     "883474662888",
 		region:      "us-west-1",
 		instanceID:  "i-01b940c45fd11fe74",
@@ -0,0 +1,269 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/cloud/awsiid"
+	"github.com/gravitational/trace"
+)
+
+// checkEC2HostClaim decides what to do when hostID already belongs to a
+// registered resource: types.RejoinPolicyDeny (the default) rejects the
+// request outright, types.RejoinPolicyChallenge requires proof the caller
+// controls the running host before rotating its certificates, and
+// types.RejoinPolicyReplace requires the existing heartbeat for the host
+// ID to have expired before handing the host ID to a new one; it does not
+// look up the prior instance's state via DescribeInstances.
+func (a *Server) checkEC2HostClaim(ctx context.Context, provisionToken types.ProvisionToken, doc *awsiid.Document, hostID string, req RegisterUsingTokenRequest) error {
+	exists, err := a.getHostIDRegistry().Contains(ctx, req.Role, hostID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !exists {
+		return nil
+	}
+
+	switch rejoinPolicy(provisionToken) {
+	case types.RejoinPolicyChallenge:
+		return a.checkRejoinChallenge(ctx, hostID, req)
+	case types.RejoinPolicyReplace:
+		return a.checkRejoinReplace(ctx, hostID)
+	default:
+		return trace.AccessDenied("instance %q has already joined the cluster", hostID)
+	}
+}
+
+// rejoinPolicy reports the token's configured RejoinPolicy, defaulting to
+// types.RejoinPolicyDeny for tokens that predate the setting.
+func rejoinPolicy(provisionToken types.ProvisionToken) types.RejoinPolicy {
+	if policy := provisionToken.GetRejoinPolicy(); policy != "" {
+		return policy
+	}
+	return types.RejoinPolicyDeny
+}
+
+const (
+	// rejoinNonceSize is the size in bytes of a rejoin challenge nonce.
+	rejoinNonceSize = 32
+	// rejoinNonceTTL is how long a candidate has to sign and return a
+	// rejoin challenge nonce before it expires.
+	rejoinNonceTTL = time.Minute
+)
+
+// RejoinChallenge is the nonce a candidate must sign, with the private
+// key matching its existing heartbeat's public key, to re-enroll a host
+// ID that's already in use under a types.RejoinPolicyChallenge token.
+type RejoinChallenge struct {
+	Nonce []byte
+}
+
+// rejoinChallengeEntry is an outstanding nonce awaiting a signed response.
+type rejoinChallengeEntry struct {
+	nonce     []byte
+	expiresAt time.Time
+}
+
+// rejoinChallenges tracks outstanding challenge-mode rejoin nonces, keyed
+// by the host ID they were issued for.
+type rejoinChallenges struct {
+	mu     sync.Mutex
+	byHost map[string]rejoinChallengeEntry
+}
+
+// newRejoinChallenges creates an empty rejoinChallenges tracker.
+func newRejoinChallenges() *rejoinChallenges {
+	return &rejoinChallenges{byHost: make(map[string]rejoinChallengeEntry)}
+}
+
+func (r *rejoinChallenges) issue(hostID string, now time.Time) ([]byte, error) {
+	nonce := make([]byte, rejoinNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byHost[hostID] = rejoinChallengeEntry{nonce: nonce, expiresAt: now.Add(rejoinNonceTTL)}
+	return nonce, nil
+}
+
+func (r *rejoinChallenges) consume(hostID string, now time.Time) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.byHost[hostID]
+	if !ok {
+		return nil, trace.AccessDenied("no outstanding rejoin challenge for host %q", hostID)
+	}
+	delete(r.byHost, hostID)
+	if now.After(entry.expiresAt) {
+		return nil, trace.AccessDenied("rejoin challenge for host %q has expired", hostID)
+	}
+	return entry.nonce, nil
+}
+
+// getRejoinChallenges returns a.rejoinChallenges, building it on first
+// use. Same rationale as getHostIDRegistry: NewServer wires this up at
+// construction time in the normal path, this is just a safety net.
+func (a *Server) getRejoinChallenges() *rejoinChallenges {
+	a.rejoinChallengesOnce.Do(func() {
+		if a.rejoinChallenges == nil {
+			a.rejoinChallenges = newRejoinChallenges()
+		}
+	})
+	return a.rejoinChallenges
+}
+
+// NewEC2RejoinChallenge issues a fresh nonce for hostID's rejoin
+// challenge. Nodes call this first when a types.RejoinPolicyChallenge
+// token reports the host ID is already taken, then sign the nonce and
+// retry RegisterUsingToken with the signature attached.
+func (a *Server) NewEC2RejoinChallenge(ctx context.Context, hostID string) (*RejoinChallenge, error) {
+	nonce, err := a.getRejoinChallenges().issue(hostID, a.clock.Now())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &RejoinChallenge{Nonce: nonce}, nil
+}
+
+// checkRejoinChallenge verifies req carries a signature, made with the key
+// already on file for hostID's heartbeat, over the outstanding challenge
+// nonce - proof the caller controls the host that's actually running
+// rather than replaying a leaked identity document against it. On success
+// it bumps hostID's certificate generation via
+// invalidatePriorHostCredentials; nothing in this file rejects
+// certificates issued under an older generation.
+func (a *Server) checkRejoinChallenge(ctx context.Context, hostID string, req RegisterUsingTokenRequest) error {
+	if len(req.RejoinChallengeResponse) == 0 {
+		return trace.AccessDenied("host %q has already joined; request a rejoin challenge and sign it to re-enroll", hostID)
+	}
+
+	nonce, err := a.getRejoinChallenges().consume(hostID, a.clock.Now())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	pub, err := a.existingHostPublicKey(ctx, hostID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if !ed25519.Verify(pub, nonce, req.RejoinChallengeResponse) {
+		return trace.AccessDenied("rejoin challenge signature for host %q does not verify", hostID)
+	}
+
+	return trace.Wrap(a.invalidatePriorHostCredentials(ctx, hostID))
+}
+
+// existingHostPublicKey returns the ed25519 public key attached to hostID's
+// current node heartbeat, used to verify a rejoin challenge response.
+func (a *Server) existingHostPublicKey(ctx context.Context, hostID string) (ed25519.PublicKey, error) {
+	node, err := a.GetNode(ctx, defaults.Namespace, hostID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return ed25519.PublicKey(node.GetRejoinPublicKey()), nil
+}
+
+// hostCertGenerations tracks, per host ID, the certificate generation a
+// challenge-mode rejoin has bumped to. RegisterUsingToken stamps the
+// current generation onto every certificate it issues for a host;
+// comparing a presented certificate's generation against this map at
+// authentication time rejects anything issued before the bump without
+// touching the certificate issued after it - unlike a types.Lock keyed
+// on ServerID, which blocks every certificate for the host, including
+// the one RegisterUsingToken is about to hand back.
+type hostCertGenerations struct {
+	mu  sync.Mutex
+	gen map[string]uint64
+}
+
+// newHostCertGenerations creates an empty hostCertGenerations tracker.
+func newHostCertGenerations() *hostCertGenerations {
+	return &hostCertGenerations{gen: make(map[string]uint64)}
+}
+
+// bump increments and returns hostID's generation.
+func (h *hostCertGenerations) bump(hostID string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.gen[hostID]++
+	return h.gen[hostID]
+}
+
+// getHostCertGenerations returns a.hostCertGenerations, building it on
+// first use. Same rationale as getRejoinChallenges and
+// getHostIDRegistry: NewServer wires this up eagerly in the normal path,
+// this is just a safety net.
+func (a *Server) getHostCertGenerations() *hostCertGenerations {
+	a.hostCertGenerationsOnce.Do(func() {
+		if a.hostCertGenerations == nil {
+			a.hostCertGenerations = newHostCertGenerations()
+		}
+	})
+	return a.hostCertGenerations
+}
+
+// invalidatePriorHostCredentials bumps hostID's certificate generation so
+// a leaked identity document or signed rejoin-challenge response can't be
+// replayed to impersonate the host a second time. RegisterUsingToken
+// stamps the bumped generation onto the certificate it issues right
+// after a successful challenge rejoin, so only certificates issued
+// before this call are invalidated, not the one replacing them.
+//
+// This used to lock out hostID's ServerID via types.Lock. That also
+// caught the brand new certificate RegisterUsingToken issues immediately
+// afterward, since a ServerID lock isn't scoped to a point in time - it
+// instantly locked out the very host the rejoin just re-enrolled.
+// Rejecting a presented certificate whose embedded
+// generation trails a.getHostCertGenerations() belongs in the
+// certificate-authentication path, which isn't part of this checkout;
+// this function only does the bookkeeping half.
+func (a *Server) invalidatePriorHostCredentials(ctx context.Context, hostID string) error {
+	a.getHostCertGenerations().bump(hostID)
+	return nil
+}
+
+// checkRejoinReplace permits RejoinPolicyReplace once hostID's existing
+// heartbeat has gone stale, so a rebooted instance - which keeps its
+// account and instance ID, and therefore its host ID, across the reboot
+// - can rejoin once the process that held the old heartbeat is gone.
+//
+// An earlier version re-derived the instance ID from the *incoming*
+// host ID and called DescribeInstances on it. A reboot doesn't change
+// the instance ID, so that always inspected the very instance making
+// the request - which is obviously running - meaning replace could
+// never succeed. Comparing against the existing heartbeat's expiry
+// instead (the same node record checkRejoinChallenge's
+// existingHostPublicKey reads the rejoin key from) makes a post-reboot
+// rejoin work the way RejoinPolicyReplace is documented to.
+func (a *Server) checkRejoinReplace(ctx context.Context, hostID string) error {
+	node, err := a.GetNode(ctx, defaults.Namespace, hostID)
+	if err != nil {
+		return trace.AccessDenied("failed to look up existing heartbeat for %q: %v", hostID, err)
+	}
+	if expiry := node.Expiry(); expiry.IsZero() || a.clock.Now().Before(expiry) {
+		return trace.AccessDenied("existing heartbeat for %q has not expired; cannot replace", hostID)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejoinChallengesIssueAndConsume(t *testing.T) {
+	c := newRejoinChallenges()
+	now := time.Now()
+
+	nonce, err := c.issue("host1", now)
+	require.NoError(t, err)
+	require.Len(t, nonce, rejoinNonceSize)
+
+	consumed, err := c.consume("host1", now.Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, nonce, consumed)
+
+	// Consuming twice fails: the nonce is one-shot.
+	_, err = c.consume("host1", now.Add(time.Second))
+	require.Error(t, err)
+}
+
+func TestRejoinChallengesExpire(t *testing.T) {
+	c := newRejoinChallenges()
+	now := time.Now()
+
+	_, err := c.issue("host1", now)
+	require.NoError(t, err)
+
+	_, err = c.consume("host1", now.Add(rejoinNonceTTL+time.Second))
+	require.Error(t, err)
+}
+
+func TestCheckRejoinReplaceRequiresStaleHeartbeat(t *testing.T) {
+	a := newAuthServer(t)
+	now := time.Now()
+	a.clock = clockwork.NewFakeClockAt(now)
+
+	const hostID = "i-old"
+	expires := now.Add(time.Minute)
+	node := &types.ServerV2{
+		Kind:    types.KindNode,
+		Version: types.V2,
+		Metadata: types.Metadata{
+			Name:      hostID,
+			Namespace: defaults.Namespace,
+			Expires:   &expires,
+		},
+	}
+	_, err := a.UpsertNode(context.Background(), node)
+	require.NoError(t, err)
+
+	require.Error(t, a.checkRejoinReplace(context.Background(), hostID), "heartbeat has not yet expired")
+
+	// Advance only a.clock, not real time: the heartbeat's TTL in the
+	// backend is untouched, but checkRejoinReplace compares against
+	// a.clock, so from its perspective the heartbeat is now stale.
+	a.clock = clockwork.NewFakeClockAt(expires.Add(time.Second))
+	require.NoError(t, a.checkRejoinReplace(context.Background(), hostID), "heartbeat has expired")
+
+	require.Error(t, a.checkRejoinReplace(context.Background(), "no-such-host"))
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/trace"
+)
+
+// JoinStream is the server-streaming counterpart to RegisterUsingToken: it
+// runs the same EC2-join validation but emits a proto.JoinStreamEvent
+// after every stage instead of only a final pass/fail, so operators get
+// actionable diagnostics during a fleet rollout (and "tctl nodes join
+// --debug" has something to show).
+func (g *GRPCServer) JoinStream(req *proto.RegisterUsingTokenRequest, stream proto.AuthService_JoinStreamServer) error {
+	// Like RegisterUsingToken, JoinStream is reached before the caller has
+	// any client certificate to authenticate with - the provision token
+	// in req is the credential - so it talks to the AuthServer directly
+	// rather than going through the authenticate()-checked ServerWithRoles
+	// path the other GRPCServer methods use.
+	internalReq := RegisterUsingTokenRequest{
+		Token:                   req.Token,
+		HostID:                  req.HostID,
+		Role:                    req.Role,
+		EC2IdentityDocument:     req.EC2IdentityDocument,
+		RejoinChallengeResponse: req.RejoinChallengeResponse,
+	}
+
+	var sendErr error
+	emit := func(event JoinStreamEvent) {
+		if sendErr != nil {
+			return
+		}
+		sendErr = stream.Send(&proto.JoinStreamEvent{
+			Kind:   string(event.Kind),
+			Code:   event.Code,
+			Stage:  string(event.Stage),
+			Detail: event.Detail,
+		})
+	}
+
+	if err := g.AuthServer.CheckEC2RequestStream(stream.Context(), internalReq, emit); err != nil {
+		if sendErr != nil {
+			return trace.Wrap(sendErr)
+		}
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(sendErr)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+)
+
+// HostIDLister reports every host ID currently registered for a resource
+// kind, so HostIDRegistry can check a candidate host ID for uniqueness
+// without the join path knowing anything about that kind.
+type HostIDLister func(ctx context.Context) ([]string, error)
+
+// HostIDRegistry tracks, per resource kind, how to list the host IDs
+// already in use. Resources register themselves at startup with
+// RegisterHostIDSource; CheckEC2Request (and any other join path) then
+// checks a candidate host ID against whichever kind it's joining as
+// without needing a hardcoded switch over every role Teleport knows
+// about.
+type HostIDRegistry struct {
+	mu      sync.RWMutex
+	sources map[types.SystemRole]HostIDLister
+}
+
+// NewHostIDRegistry creates an empty HostIDRegistry.
+func NewHostIDRegistry() *HostIDRegistry {
+	return &HostIDRegistry{
+		sources: make(map[types.SystemRole]HostIDLister),
+	}
+}
+
+// RegisterHostIDSource registers lister as the way to enumerate host IDs
+// already in use for kind. A later call for the same kind replaces the
+// previous lister.
+func (r *HostIDRegistry) RegisterHostIDSource(kind types.SystemRole, lister HostIDLister) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources[kind] = lister
+}
+
+// Contains reports whether hostID is already in use for kind. A kind with
+// no registered source is treated as never containing any host ID, the
+// same as the old switch's default case.
+func (r *HostIDRegistry) Contains(ctx context.Context, kind types.SystemRole, hostID string) (bool, error) {
+	r.mu.RLock()
+	lister, ok := r.sources[kind]
+	r.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+
+	ids, err := lister(ctx)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	for _, id := range ids {
+		if id == hostID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getHostIDRegistry returns a.hostIDRegistry, building it with the
+// default sources on first use. NewServer is expected to wire this up
+// eagerly at construction time; this lazy fallback exists so any *Server
+// that skips that step (a test constructing one directly, say) still
+// gets a working registry instead of a nil-pointer panic the first time
+// a join request needs it.
+func (a *Server) getHostIDRegistry() *HostIDRegistry {
+	a.hostIDRegistryOnce.Do(func() {
+		if a.hostIDRegistry == nil {
+			a.hostIDRegistry = NewHostIDRegistry()
+			registerDefaultHostIDSources(a, a.hostIDRegistry)
+		}
+	})
+	return a.hostIDRegistry
+}
+
+// registerDefaultHostIDSources wires up the host ID sources for the roles
+// that CheckEC2Request's uniqueness check used to enumerate by hand:
+// nodes, proxies, kube services, database servers, and application
+// servers. Called once during server construction.
+func registerDefaultHostIDSources(a *Server, registry *HostIDRegistry) {
+	registry.RegisterHostIDSource(types.RoleNode, func(ctx context.Context) ([]string, error) {
+		nodes, err := a.GetNodes(ctx, defaults.Namespace)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		ids := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			ids = append(ids, n.GetName())
+		}
+		return ids, nil
+	})
+
+	registry.RegisterHostIDSource(types.RoleProxy, func(ctx context.Context) ([]string, error) {
+		proxies, err := a.GetProxies()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		ids := make([]string, 0, len(proxies))
+		for _, p := range proxies {
+			ids = append(ids, p.GetName())
+		}
+		return ids, nil
+	})
+
+	registry.RegisterHostIDSource(types.RoleKube, func(ctx context.Context) ([]string, error) {
+		services, err := a.GetKubeServices(ctx)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		ids := make([]string, 0, len(services))
+		for _, s := range services {
+			ids = append(ids, s.GetName())
+		}
+		return ids, nil
+	})
+
+	registry.RegisterHostIDSource(types.RoleDatabase, func(ctx context.Context) ([]string, error) {
+		servers, err := a.GetDatabaseServers(ctx, defaults.Namespace)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		ids := make([]string, 0, len(servers))
+		for _, s := range servers {
+			ids = append(ids, s.GetHostID())
+		}
+		return ids, nil
+	})
+
+	registry.RegisterHostIDSource(types.RoleApp, func(ctx context.Context) ([]string, error) {
+		servers, err := a.GetApplicationServers(ctx, defaults.Namespace)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		ids := make([]string, 0, len(servers))
+		for _, s := range servers {
+			ids = append(ids, s.GetName())
+		}
+		return ids, nil
+	})
+}
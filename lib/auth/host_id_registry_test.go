@@ -0,0 +1,87 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostIDRegistryContains(t *testing.T) {
+	registry := NewHostIDRegistry()
+	registry.RegisterHostIDSource(types.RoleNode, func(ctx context.Context) ([]string, error) {
+		return []string{"account-instance1", "account-instance2"}, nil
+	})
+
+	exists, err := registry.Contains(context.Background(), types.RoleNode, "account-instance1")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = registry.Contains(context.Background(), types.RoleNode, "account-instance3")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestHostIDRegistryUnregisteredKind(t *testing.T) {
+	registry := NewHostIDRegistry()
+
+	exists, err := registry.Contains(context.Background(), types.RoleWindowsDesktop, "whatever")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestHostIDRegistryPropagatesListerError(t *testing.T) {
+	registry := NewHostIDRegistry()
+	registry.RegisterHostIDSource(types.RoleNode, func(ctx context.Context) ([]string, error) {
+		return nil, trace.ConnectionProblem(nil, "backend unavailable")
+	})
+
+	_, err := registry.Contains(context.Background(), types.RoleNode, "account-instance1")
+	require.Error(t, err)
+}
+
+// TestHostUniqueCheckRegistryDriven replaces the old hand-written switch
+// over every joinable role: checkHostUnique now only knows about
+// a.hostIDRegistry, so each kind is exercised by registering a fake
+// source rather than by upserting a real resource of that kind.
+func TestHostUniqueCheckRegistryDriven(t *testing.T) {
+	for _, role := range []types.SystemRole{
+		types.RoleNode,
+		types.RoleProxy,
+		types.RoleKube,
+		types.RoleDatabase,
+		types.RoleApp,
+	} {
+		t.Run(role.String(), func(t *testing.T) {
+			registry := NewHostIDRegistry()
+			registry.RegisterHostIDSource(role, func(ctx context.Context) ([]string, error) {
+				return []string{"account-existing"}, nil
+			})
+			a := &Server{hostIDRegistry: registry}
+
+			err := a.checkHostUnique(context.Background(), "account-existing", role)
+			require.True(t, trace.IsAccessDenied(err))
+
+			err = a.checkHostUnique(context.Background(), "account-new", role)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	imdsTokenURL       = "http://169.254.169.254/latest/api/token"
+	imdsIdentityURL    = "http://169.254.169.254/latest/dynamic/instance-identity/rsa2048"
+	imdsTokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+	imdsTokenHeader    = "X-aws-ec2-metadata-token"
+	imdsTokenTTL       = "21600" // 6 hours, the IMDSv2 default.
+)
+
+// GetEC2IdentityDocument fetches the PKCS#7-signed EC2 instance identity
+// document via IMDSv2: a session token is requested first with a
+// PUT to /latest/api/token, then used to authenticate the GET for the
+// signed document itself. This is what CheckEC2Request on the auth side
+// expects to verify.
+func GetEC2IdentityDocument(ctx context.Context) ([]byte, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	token, err := fetchIMDSv2Token(ctx, client)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imdsIdentityURL, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	req.Header.Set(imdsTokenHeader, token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, trace.BadParameter("unexpected status %d fetching instance identity document", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return body, nil
+}
+
+// fetchIMDSv2Token obtains a session token used to authenticate subsequent
+// IMDSv2 metadata requests.
+func fetchIMDSv2Token(ctx context.Context, client *http.Client) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, imdsTokenURL, nil)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	req.Header.Set(imdsTokenTTLHeader, imdsTokenTTL)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", trace.BadParameter("unexpected status %d fetching IMDSv2 token", resp.StatusCode)
+	}
+
+	token, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return string(token), nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package awsiid verifies the PKCS#7-signed EC2 instance identity
+// document, independent of how the auth server dispatches a join request.
+// Its caller is lib/auth.Server.CheckEC2Request (via
+// CheckEC2RequestStream); lib/auth/cloudjoin has no AWS Verifier.
+package awsiid
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"time"
+
+	"github.com/fullsailor/pkcs7"
+	"github.com/gravitational/trace"
+)
+
+// Document is the subset of the EC2 instance identity document that
+// callers care about.
+type Document struct {
+	AccountID   string    `json:"accountId"`
+	Region      string    `json:"region"`
+	InstanceID  string    `json:"instanceId"`
+	PendingTime time.Time `json:"pendingTime"`
+}
+
+// ParseAndVerify verifies the PKCS#7 signature on an EC2 instance identity
+// document against every cert in certsPEM, trying each in turn since a
+// request doesn't say which AWS partition it came from, and returns its
+// parsed contents.
+func ParseAndVerify(iidBytes []byte, certsPEM [][]byte) (*Document, error) {
+	p7, err := pkcs7.Parse(iidBytes)
+	if err != nil {
+		return nil, trace.AccessDenied("invalid identity document: %v", err)
+	}
+
+	var verifyErr error
+	verified := false
+	for _, certPEM := range certsPEM {
+		cert, err := parseCertPEM(certPEM)
+		if err != nil {
+			verifyErr = err
+			continue
+		}
+		p7.Certificates = []*x509.Certificate{cert}
+		if err := p7.Verify(); err != nil {
+			verifyErr = err
+			continue
+		}
+		verified = true
+		break
+	}
+	if !verified {
+		return nil, trace.AccessDenied("identity document signature verification failed: %v", verifyErr)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(p7.Content, &doc); err != nil {
+		return nil, trace.AccessDenied("failed to parse identity document: %v", err)
+	}
+	return &doc, nil
+}
+
+// parseCertPEM decodes a single PEM-encoded X.509 certificate.
+func parseCertPEM(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, trace.BadParameter("invalid PEM block")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/lib/cloud/aws"
+	"github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// awsNamespace is used as the namespace prefix for any labels imported from
+// AWS, preserving the prefix the EC2-only importer used.
+const awsNamespace = "aws"
+
+// awsProvider imports tags via the EC2 IMDSv2 client, preserving the
+// behavior of the original lib/labels/ec2 importer.
+type awsProvider struct {
+	client aws.InstanceMetadata
+}
+
+// NewAWSProvider returns a Provider that imports EC2 instance tags via
+// IMDSv2. The underlying client is created lazily on first Detect/FetchTags
+// call so constructing the provider never touches the network.
+func NewAWSProvider() Provider {
+	return &awsProvider{}
+}
+
+func (p *awsProvider) ensureClient(ctx context.Context) error {
+	if p.client != nil {
+		return nil
+	}
+	client, err := utils.NewInstanceMetadataClient(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.client = client
+	return nil
+}
+
+// Detect reports whether this agent is running on an EC2 instance.
+func (p *awsProvider) Detect(ctx context.Context) bool {
+	if err := p.ensureClient(ctx); err != nil {
+		return false
+	}
+	return p.client.IsAvailable(ctx)
+}
+
+// Namespace returns the "aws" label prefix.
+func (p *awsProvider) Namespace() string {
+	return awsNamespace
+}
+
+// FetchTags fetches every instance tag key/value pair using the IMDSv2
+// token-based token+tags flow.
+func (p *awsProvider) FetchTags(ctx context.Context) (map[string]string, error) {
+	if err := p.ensureClient(ctx); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	keys, err := p.client.GetTagKeys(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	tags := make(map[string]string, len(keys))
+	for _, k := range keys {
+		value, err := p.client.GetTagValue(ctx, k)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		tags[k] = value
+	}
+	return tags, nil
+}
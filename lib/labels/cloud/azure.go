@@ -0,0 +1,111 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// azureNamespace is used as the namespace prefix for any labels imported
+// from Azure.
+const azureNamespace = "azure"
+
+// azureMetadataURL is the Azure Instance Metadata Service endpoint for
+// instance-level data, including tags.
+const azureMetadataURL = "http://169.254.169.254/metadata/instance?api-version=2021-02-01"
+
+// azureInstanceDocument is the subset of the IMDS instance document this
+// provider cares about.
+type azureInstanceDocument struct {
+	Compute struct {
+		TagsList []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"tagsList"`
+	} `json:"compute"`
+}
+
+// azureProvider imports tags from the Azure Instance Metadata Service.
+type azureProvider struct {
+	client *http.Client
+	// url is the IMDS endpoint to query; overridden in tests.
+	url string
+}
+
+// NewAzureProvider returns a Provider that imports VM tags from the Azure
+// Instance Metadata Service.
+func NewAzureProvider() Provider {
+	return &azureProvider{
+		client: &http.Client{Timeout: 5 * time.Second},
+		url:    azureMetadataURL,
+	}
+}
+
+func (p *azureProvider) get(ctx context.Context) (*azureInstanceDocument, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	req.Header.Set("Metadata", "true")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, trace.BadParameter("unexpected status %d from Azure IMDS", resp.StatusCode)
+	}
+
+	var doc azureInstanceDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &doc, nil
+}
+
+// Detect reports whether the Azure IMDS endpoint is reachable.
+func (p *azureProvider) Detect(ctx context.Context) bool {
+	_, err := p.get(ctx)
+	return err == nil
+}
+
+// Namespace returns the "azure" label prefix.
+func (p *azureProvider) Namespace() string {
+	return azureNamespace
+}
+
+// FetchTags returns the VM's tags, as set in the Azure portal or ARM
+// template.
+func (p *azureProvider) FetchTags(ctx context.Context) (map[string]string, error) {
+	doc, err := p.get(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	tags := make(map[string]string, len(doc.Compute.TagsList))
+	for _, t := range doc.Compute.TagsList {
+		tags[t.Name] = t.Value
+	}
+	return tags, nil
+}
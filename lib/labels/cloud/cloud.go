@@ -0,0 +1,215 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloud imports resource labels from whatever cloud instance
+// metadata service the agent happens to be running under. It replaces the
+// AWS-only lib/labels/ec2 importer with a Provider abstraction so AWS,
+// Azure, and GCP (and, eventually, hybrid deployments that see more than
+// one at once) are all handled the same way.
+package cloud
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// labelUpdatePeriod is the default period for refreshing cloud labels.
+const labelUpdatePeriod = time.Hour
+
+// Provider fetches resource labels from a single cloud's instance metadata
+// service.
+type Provider interface {
+	// Detect reports whether the agent appears to be running in this
+	// provider's environment. It must be safe to call even when the
+	// provider isn't present (e.g. the metadata endpoint isn't reachable).
+	Detect(ctx context.Context) bool
+	// Namespace is the label prefix this provider's tags are imported
+	// under, e.g. "aws", "azure", or "gcp".
+	Namespace() string
+	// FetchTags returns the instance's tags, unprefixed.
+	FetchTags(ctx context.Context) (map[string]string, error)
+}
+
+// Config is the configuration for the cloud label importer.
+type Config struct {
+	// Providers is the set of providers to query. If nil, all known
+	// providers are probed with Detect and the ones that match are used.
+	Providers []Provider
+	// Clock is used to control the refresh ticker in tests.
+	Clock clockwork.Clock
+	// Log is the logger used to report per-provider fetch errors.
+	Log *logrus.Entry
+	// UpdatePeriod is how often labels are refreshed. Defaults to one hour.
+	UpdatePeriod time.Duration
+	// NamespaceOverrides remaps a provider's default namespace to a custom
+	// one, keyed by the provider's default namespace.
+	NamespaceOverrides map[string]string
+	// Allow, if non-empty, restricts imported label keys (after namespacing)
+	// to this set.
+	Allow []string
+	// Deny removes label keys (after namespacing) from the imported set,
+	// applied after Allow.
+	Deny []string
+}
+
+func (c *Config) checkAndSetDefaults(ctx context.Context) error {
+	if c.Clock == nil {
+		c.Clock = clockwork.NewRealClock()
+	}
+	if c.Log == nil {
+		c.Log = logrus.NewEntry(logrus.StandardLogger())
+	}
+	if c.UpdatePeriod == 0 {
+		c.UpdatePeriod = labelUpdatePeriod
+	}
+	if c.Providers == nil {
+		var detected []Provider
+		for _, p := range defaultProviders() {
+			if p.Detect(ctx) {
+				detected = append(detected, p)
+			}
+		}
+		if len(detected) == 0 {
+			return trace.NotFound("no cloud instance metadata provider detected")
+		}
+		c.Providers = detected
+	}
+	return nil
+}
+
+// defaultProviders returns a fresh instance of every known Provider
+// implementation, used for auto-detection.
+func defaultProviders() []Provider {
+	return []Provider{
+		NewAWSProvider(),
+		NewAzureProvider(),
+		NewGCPProvider(),
+	}
+}
+
+// Importer is a service that periodically imports labels from one or more
+// cloud instance-metadata providers, merging the results when several
+// respond (as can happen in hybrid/edge deployments).
+type Importer struct {
+	c      *Config
+	mu     sync.RWMutex
+	labels map[string]string
+
+	closeCh chan struct{}
+}
+
+// NewImporter creates a cloud label importer. Providers that are not given
+// explicitly via Config.Providers are auto-detected.
+func NewImporter(ctx context.Context, c *Config) (*Importer, error) {
+	if err := c.checkAndSetDefaults(ctx); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Importer{
+		c:       c,
+		labels:  make(map[string]string),
+		closeCh: make(chan struct{}),
+	}, nil
+}
+
+// Get returns the most recently imported set of labels, merged across all
+// active providers.
+func (importer *Importer) Get() map[string]string {
+	importer.mu.RLock()
+	defer importer.mu.RUnlock()
+	return importer.labels
+}
+
+// Sync blocks and synchronously refreshes labels from every configured
+// provider.
+func (importer *Importer) Sync(ctx context.Context) {
+	merged := make(map[string]string)
+
+	for _, p := range importer.c.Providers {
+		tags, err := p.FetchTags(ctx)
+		if err != nil {
+			importer.c.Log.WithField("namespace", p.Namespace()).Errorf("Error fetching cloud tags: %v", err)
+			continue
+		}
+		namespace := p.Namespace()
+		if override, ok := importer.c.NamespaceOverrides[namespace]; ok {
+			namespace = override
+		}
+		for k, v := range namespaceLabels(namespace, tags) {
+			merged[k] = v
+		}
+	}
+
+	merged = filterLabels(merged, importer.c.Allow, importer.c.Deny)
+
+	importer.mu.Lock()
+	defer importer.mu.Unlock()
+	importer.labels = merged
+}
+
+// Start starts a loop that continually keeps cloud labels updated.
+func (importer *Importer) Start(ctx context.Context) {
+	go importer.periodicUpdateLabels(ctx)
+}
+
+func (importer *Importer) periodicUpdateLabels(ctx context.Context) {
+	ticker := importer.c.Clock.NewTicker(importer.c.UpdatePeriod)
+	defer ticker.Stop()
+
+	for {
+		importer.Sync(ctx)
+		select {
+		case <-ticker.Chan():
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// namespaceLabels prefixes every label key with the given namespace.
+func namespaceLabels(namespace string, labels map[string]string) map[string]string {
+	m := make(map[string]string, len(labels))
+	for k, v := range labels {
+		m[namespace+"/"+k] = v
+	}
+	return m
+}
+
+// filterLabels applies an allow-list (if non-empty) followed by a deny-list
+// to a namespaced label set.
+func filterLabels(labels map[string]string, allow, deny []string) map[string]string {
+	if len(allow) > 0 {
+		allowed := make(map[string]string, len(allow))
+		allowSet := make(map[string]struct{}, len(allow))
+		for _, k := range allow {
+			allowSet[k] = struct{}{}
+		}
+		for k, v := range labels {
+			if _, ok := allowSet[k]; ok {
+				allowed[k] = v
+			}
+		}
+		labels = allowed
+	}
+	for _, k := range deny {
+		delete(labels, k)
+	}
+	return labels
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"compute":{"tagsList":[{"name":"env","value":"prod"},{"name":"team","value":"core"}]}}`)
+	}))
+	defer srv.Close()
+
+	p := &azureProvider{client: srv.Client(), url: srv.URL}
+	tags, err := p.FetchTags(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"env": "prod", "team": "core"}, tags)
+	require.Equal(t, "azure", p.Namespace())
+}
+
+func TestGCPProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, "env\nteam\n")
+		case "/env":
+			fmt.Fprint(w, "prod")
+		case "/team":
+			fmt.Fprint(w, "core")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p := &gcpProvider{client: srv.Client(), attributesURL: srv.URL + "/"}
+	tags, err := p.FetchTags(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"env": "prod", "team": "core"}, tags)
+	require.Equal(t, "gcp", p.Namespace())
+}
+
+type fakeProvider struct {
+	namespace string
+	tags      map[string]string
+}
+
+func (f *fakeProvider) Detect(ctx context.Context) bool { return true }
+func (f *fakeProvider) Namespace() string                { return f.namespace }
+func (f *fakeProvider) FetchTags(ctx context.Context) (map[string]string, error) {
+	return f.tags, nil
+}
+
+func TestImporterMergesProviders(t *testing.T) {
+	importer, err := NewImporter(context.Background(), &Config{
+		Providers: []Provider{
+			&fakeProvider{namespace: "aws", tags: map[string]string{"env": "prod"}},
+			&fakeProvider{namespace: "gcp", tags: map[string]string{"zone": "us-central1-a"}},
+		},
+	})
+	require.NoError(t, err)
+
+	importer.Sync(context.Background())
+	require.Equal(t, map[string]string{
+		"aws/env":  "prod",
+		"gcp/zone": "us-central1-a",
+	}, importer.Get())
+}
+
+func TestImporterAppliesAllowDenyAndNamespaceOverride(t *testing.T) {
+	importer, err := NewImporter(context.Background(), &Config{
+		Providers: []Provider{
+			&fakeProvider{namespace: "aws", tags: map[string]string{"env": "prod", "secret": "x"}},
+		},
+		NamespaceOverrides: map[string]string{"aws": "cloud"},
+		Allow:              []string{"cloud/env", "cloud/secret"},
+		Deny:               []string{"cloud/secret"},
+	})
+	require.NoError(t, err)
+
+	importer.Sync(context.Background())
+	require.Equal(t, map[string]string{"cloud/env": "prod"}, importer.Get())
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// gcpNamespace is used as the namespace prefix for any labels imported
+// from GCP.
+const gcpNamespace = "gcp"
+
+// gcpAttributesURL lists the custom metadata attributes attached to a GCE
+// instance, which Teleport imports the same way it imports AWS/Azure tags.
+const gcpAttributesURL = "http://metadata.google.internal/computeMetadata/v1/instance/attributes/"
+
+// gcpProvider imports custom metadata attributes from the GCE metadata
+// server.
+type gcpProvider struct {
+	client *http.Client
+	// attributesURL is the base attributes endpoint to query; overridden
+	// in tests.
+	attributesURL string
+}
+
+// NewGCPProvider returns a Provider that imports instance attributes from
+// the GCE metadata server.
+func NewGCPProvider() Provider {
+	return &gcpProvider{
+		client:        &http.Client{Timeout: 5 * time.Second},
+		attributesURL: gcpAttributesURL,
+	}
+}
+
+func (p *gcpProvider) get(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", trace.BadParameter("unexpected status %d from GCP metadata server", resp.StatusCode)
+	}
+
+	var sb strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		sb.WriteString(scanner.Text())
+		sb.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return "", trace.Wrap(err)
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
+
+// Detect reports whether the GCE metadata server is reachable.
+func (p *gcpProvider) Detect(ctx context.Context) bool {
+	_, err := p.get(ctx, p.attributesURL)
+	return err == nil
+}
+
+// Namespace returns the "gcp" label prefix.
+func (p *gcpProvider) Namespace() string {
+	return gcpNamespace
+}
+
+// FetchTags lists every custom metadata attribute set on the instance and
+// fetches its value.
+func (p *gcpProvider) FetchTags(ctx context.Context) (map[string]string, error) {
+	listing, err := p.get(ctx, p.attributesURL)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	tags := make(map[string]string)
+	for _, name := range strings.Split(listing, "\n") {
+		if name == "" {
+			continue
+		}
+		value, err := p.get(ctx, p.attributesURL+name)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		tags[name] = value
+	}
+	return tags, nil
+}
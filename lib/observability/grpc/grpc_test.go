@@ -0,0 +1,86 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryUnaryInterceptorConvertsPanic(t *testing.T) {
+	interceptor := recoveryUnaryInterceptor(logrus.NewEntry(logrus.StandardLogger()))
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/teleport.proxy.v1.ProxyService/DialNode"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	_, err := interceptor(context.Background(), nil, info, handler)
+	require.Error(t, err)
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRecoveryUnaryInterceptorPassesThrough(t *testing.T) {
+	interceptor := recoveryUnaryInterceptor(logrus.NewEntry(logrus.StandardLogger()))
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/teleport.proxy.v1.ProxyService/DialNode"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	resp, err := interceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+}
+
+func TestMetricsUnaryInterceptorTracksActiveStreams(t *testing.T) {
+	interceptor := metricsUnaryInterceptor()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/teleport.proxy.v1.ProxyService/DialNode"}
+	inHandler := make(chan struct{})
+	release := make(chan struct{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		close(inHandler)
+		<-release
+		return nil, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = interceptor(context.Background(), nil, info, handler)
+		close(done)
+	}()
+
+	<-inHandler
+	require.Equal(t, float64(1), testutil.ToFloat64(activeStreams.WithLabelValues("ProxyService", "DialNode")))
+	close(release)
+	<-done
+	require.Equal(t, float64(0), testutil.ToFloat64(activeStreams.WithLabelValues("ProxyService", "DialNode")))
+}
+
+func TestNewGRPCServerInstallsInterceptors(t *testing.T) {
+	srv := NewGRPCServer(ServerOption{Log: logrus.NewEntry(logrus.StandardLogger())})
+	require.NotNil(t, srv)
+	require.NotNil(t, srv.GetServiceInfo())
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gravitational/trace"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	addressEventsSent = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "teleport",
+		Subsystem: "proxy_peer",
+		Name:      "address_events_sent_total",
+		Help:      "Number of server address change events sent to peer proxies.",
+	})
+	addressEventsReceived = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "teleport",
+		Subsystem: "proxy_peer",
+		Name:      "address_events_received_total",
+		Help:      "Number of server address change events received from peer proxies.",
+	})
+	addressEventsDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "teleport",
+		Subsystem: "proxy_peer",
+		Name:      "address_events_dropped_total",
+		Help:      "Number of server address change events dropped because a subscriber's backlog overflowed its resume buffer.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(addressEventsSent, addressEventsReceived, addressEventsDropped)
+}
+
+// backlogSize bounds how many past events AddressChangeBroadcaster keeps
+// around so a reconnecting peer can resume from its last cursor instead of
+// missing events entirely. It's a var, rather than a const, so tests can
+// shrink it without publishing thousands of events.
+var backlogSize = 4096
+
+// ServerAddressChange describes a heartbeat-driven change to a server's
+// advertised or tunnel address, as pushed over the StreamServerAddresses
+// RPC so peer proxies can update their routing tables without waiting for
+// the next watch cycle.
+type ServerAddressChange struct {
+	// Cursor uniquely identifies this event's position in the broadcaster's
+	// history, used by reconnecting subscribers to resume without gaps.
+	Cursor uint64
+	// HostID is the ID of the server whose address changed.
+	HostID string
+	// Kind is the server's resource kind, e.g. types.KindNode.
+	Kind string
+	// OldAddr is the address that was previously advertised, if any.
+	OldAddr string
+	// NewAddr is the newly advertised address.
+	NewAddr string
+}
+
+// AddressChangeBroadcaster fans out ServerAddressChange events, derived
+// from the auth server's server-heartbeat watcher, to every proxy peer
+// subscribed via StreamServerAddresses. It keeps a bounded backlog so a
+// peer that reconnects can resume from its last seen cursor rather than
+// silently missing events.
+type AddressChangeBroadcaster struct {
+	mu          sync.Mutex
+	nextCursor  uint64
+	backlog     []ServerAddressChange
+	subscribers map[chan ServerAddressChange]struct{}
+}
+
+// NewAddressChangeBroadcaster creates an empty broadcaster.
+func NewAddressChangeBroadcaster() *AddressChangeBroadcaster {
+	return &AddressChangeBroadcaster{
+		subscribers: make(map[chan ServerAddressChange]struct{}),
+	}
+}
+
+// Publish records a server address change and fans it out to every current
+// subscriber. Subscribers that can't keep up have the event counted as
+// dropped rather than blocking the publisher.
+func (b *AddressChangeBroadcaster) Publish(hostID, kind, oldAddr, newAddr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := ServerAddressChange{
+		Cursor:  b.nextCursor,
+		HostID:  hostID,
+		Kind:    kind,
+		OldAddr: oldAddr,
+		NewAddr: newAddr,
+	}
+	b.nextCursor++
+
+	b.backlog = append(b.backlog, event)
+	if len(b.backlog) > backlogSize {
+		b.backlog = b.backlog[len(b.backlog)-backlogSize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+			addressEventsSent.Inc()
+		default:
+			addressEventsDropped.Inc()
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns a channel of events plus
+// an unsubscribe function. If afterCursor is non-nil, any backlogged events
+// with a cursor greater than *afterCursor are replayed to the channel
+// before live events start flowing, so a resuming peer doesn't miss
+// anything that happened while it was disconnected.
+func (b *AddressChangeBroadcaster) Subscribe(afterCursor *uint64) (<-chan ServerAddressChange, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan ServerAddressChange, backlogSize)
+	if afterCursor != nil {
+		resumeFrom, err := resumeIndex(b.backlog, *afterCursor)
+		if err != nil {
+			close(ch)
+			return nil, nil, trace.Wrap(err)
+		}
+		for _, event := range b.backlog[resumeFrom:] {
+			ch <- event
+		}
+	}
+
+	b.subscribers[ch] = struct{}{}
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// resumeIndex finds the backlog index to resume from after the given
+// cursor. If the cursor is older than everything still in the backlog, it
+// returns a NotFound error so the caller can fall back to a full resync
+// instead of silently skipping events.
+func resumeIndex(backlog []ServerAddressChange, afterCursor uint64) (int, error) {
+	if len(backlog) == 0 {
+		return 0, nil
+	}
+	if backlog[0].Cursor > afterCursor+1 {
+		return 0, trace.NotFound("resume cursor %d is older than the retained backlog, a full resync is required", afterCursor)
+	}
+	for i, event := range backlog {
+		if event.Cursor > afterCursor {
+			return i, nil
+		}
+	}
+	return len(backlog), nil
+}
+
+// StreamTo pushes events from the broadcaster to send, stopping when ctx is
+// canceled or send returns an error. It's the shared loop the gRPC
+// StreamServerAddresses handler and any in-process consumer use so the
+// event-forwarding logic isn't duplicated across transports.
+func StreamTo(ctx context.Context, events <-chan ServerAddressChange, send func(ServerAddressChange) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return trace.Wrap(ctx.Err())
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := send(event); err != nil {
+				return trace.Wrap(err)
+			}
+			addressEventsReceived.Inc()
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+// backlogSizeForTest temporarily shrinks the package-level backlog size so
+// tests can exercise backlog eviction without publishing thousands of
+// events, restoring the original value on cleanup.
+func backlogSizeForTest(n int) (restore func()) {
+	orig := backlogSize
+	backlogSize = n
+	return func() { backlogSize = orig }
+}
+
+func TestAddressChangeBroadcasterLiveDelivery(t *testing.T) {
+	b := NewAddressChangeBroadcaster()
+
+	ch, unsubscribe, err := b.Subscribe(nil)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	b.Publish("host-1", "node", "10.0.0.1:3022", "10.0.0.2:3022")
+
+	event := <-ch
+	require.Equal(t, "host-1", event.HostID)
+	require.Equal(t, "10.0.0.2:3022", event.NewAddr)
+}
+
+func TestAddressChangeBroadcasterResumesFromCursor(t *testing.T) {
+	b := NewAddressChangeBroadcaster()
+
+	b.Publish("host-1", "node", "", "10.0.0.1:3022")
+	b.Publish("host-2", "node", "", "10.0.0.2:3022")
+	b.Publish("host-3", "node", "", "10.0.0.3:3022")
+
+	resumeFrom := uint64(0)
+	ch, unsubscribe, err := b.Subscribe(&resumeFrom)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	first := <-ch
+	require.Equal(t, "host-2", first.HostID)
+	second := <-ch
+	require.Equal(t, "host-3", second.HostID)
+}
+
+func TestAddressChangeBroadcasterRejectsStaleCursor(t *testing.T) {
+	restore := backlogSizeForTest(2)
+	defer restore()
+
+	b := NewAddressChangeBroadcaster()
+	b.Publish("host-1", "node", "", "10.0.0.1:3022")
+	b.Publish("host-2", "node", "", "10.0.0.2:3022")
+	b.Publish("host-3", "node", "", "10.0.0.3:3022")
+	b.Publish("host-4", "node", "", "10.0.0.4:3022")
+
+	stale := uint64(0)
+	_, _, err := b.Subscribe(&stale)
+	require.True(t, trace.IsNotFound(err))
+}
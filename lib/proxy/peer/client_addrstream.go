@@ -0,0 +1,61 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/trace"
+)
+
+// SubscribeServerAddresses makes one attempt at a peer proxy's
+// StreamServerAddresses RPC, applying every event it sends to table so
+// this proxy's view of where to dial a peer-routed server stays current
+// without waiting for its own next watch cycle. It returns once ctx is
+// canceled or the stream ends; callers are expected to retry with
+// backoff and pass afterCursor back in on the next call so the replay
+// picks up where this one left off, the same pattern this codebase uses
+// for every other watch-and-reconnect loop.
+func SubscribeServerAddresses(ctx context.Context, client proto.ProxyServiceClient, table RoutingTable, afterCursor *uint64) (lastCursor uint64, err error) {
+	req := &proto.StreamServerAddressesRequest{}
+	if afterCursor != nil {
+		req.Resume = true
+		req.AfterCursor = *afterCursor
+		// An attempt that receives no events hands the same cursor back.
+		lastCursor = *afterCursor
+	}
+
+	stream, err := client.StreamServerAddresses(ctx, req)
+	if err != nil {
+		return lastCursor, trace.Wrap(err)
+	}
+
+	for {
+		change, err := stream.Recv()
+		if err != nil {
+			return lastCursor, trace.Wrap(err)
+		}
+
+		if change.NewAddr == "" {
+			table.RemoveServerAddr(change.HostId)
+		} else {
+			table.UpdateServerAddr(change.HostId, change.NewAddr)
+		}
+		lastCursor = change.Cursor
+	}
+}
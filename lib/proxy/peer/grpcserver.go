@@ -0,0 +1,39 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"github.com/gravitational/teleport/api/client/proto"
+	grpcobservability "github.com/gravitational/teleport/lib/observability/grpc"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// GRPCServer builds the *grpc.Server the proxy-peer listener accepts
+// connections on: grpcobservability.NewGRPCServer installs the shared
+// panic-recovery and metrics interceptor chain, then s is registered as
+// the ProxyService implementation. Every internal gRPC server is meant to
+// be built through grpcobservability.NewGRPCServer rather than calling
+// grpc.NewServer directly, so proxy peering can't accidentally ship
+// without it.
+func (s *Server) GRPCServer(extra ...grpc.ServerOption) *grpc.Server {
+	grpcServer := grpcobservability.NewGRPCServer(grpcobservability.ServerOption{
+		Log: logrus.WithField("component", "proxy-peer"),
+	}, extra...)
+	proto.RegisterProxyServiceServer(grpcServer, s)
+	return grpcServer
+}
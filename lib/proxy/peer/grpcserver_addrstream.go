@@ -0,0 +1,52 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/trace"
+)
+
+// StreamServerAddresses is the server-streaming RPC peer proxies use to
+// keep their routing tables current without waiting for the next watch
+// cycle: the broadcaster this Server was built with is fed by
+// AddressChangeBroadcaster.WatchHeartbeats, and every event is forwarded
+// to the requesting peer as it's published. If req.Resume is set,
+// backlogged events after req.AfterCursor are replayed first so a
+// reconnecting peer doesn't miss anything.
+func (s *Server) StreamServerAddresses(req *proto.StreamServerAddressesRequest, stream proto.ProxyService_StreamServerAddressesServer) error {
+	var afterCursor *uint64
+	if req.Resume {
+		afterCursor = &req.AfterCursor
+	}
+
+	events, unsubscribe, err := s.addressChanges.Subscribe(afterCursor)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer unsubscribe()
+
+	return trace.Wrap(StreamTo(stream.Context(), events, func(change ServerAddressChange) error {
+		return stream.Send(&proto.ServerAddressChange{
+			Cursor:  change.Cursor,
+			HostId:  change.HostID,
+			Kind:    change.Kind,
+			OldAddr: change.OldAddr,
+			NewAddr: change.NewAddr,
+		})
+	}))
+}
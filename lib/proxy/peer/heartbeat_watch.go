@@ -0,0 +1,72 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+)
+
+// addressedResource is the subset of a heartbeat resource
+// AddressChangeBroadcaster needs to turn it into a ServerAddressChange.
+// types.Server, types.DatabaseServer, and types.AppServer all satisfy it.
+type addressedResource interface {
+	GetName() string
+	GetKind() string
+	GetAddr() string
+}
+
+// WatchHeartbeats feeds Publish from the auth server's heartbeat watcher:
+// every upsert of a node/proxy/kube/database/app resource whose address
+// changed is turned into a ServerAddressChange, and every delete clears
+// it. It runs until ctx is canceled or the watcher closes, and is meant
+// to run for the lifetime of the proxy process.
+func (b *AddressChangeBroadcaster) WatchHeartbeats(ctx context.Context, watcher types.Watcher) error {
+	lastAddr := make(map[string]string)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return trace.Wrap(ctx.Err())
+		case <-watcher.Done():
+			return trace.Wrap(watcher.Error())
+		case event := <-watcher.Events():
+			resource, ok := event.Resource.(addressedResource)
+			if !ok {
+				continue
+			}
+			hostID := resource.GetName()
+
+			if event.Type == types.OpDelete {
+				oldAddr := lastAddr[hostID]
+				delete(lastAddr, hostID)
+				b.Publish(hostID, resource.GetKind(), oldAddr, "")
+				continue
+			}
+
+			newAddr := resource.GetAddr()
+			oldAddr := lastAddr[hostID]
+			if newAddr == oldAddr {
+				continue
+			}
+			lastAddr[hostID] = newAddr
+			b.Publish(hostID, resource.GetKind(), oldAddr, newAddr)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAddressedResource struct {
+	name, kind, addr string
+}
+
+func (r fakeAddressedResource) GetName() string { return r.name }
+func (r fakeAddressedResource) GetKind() string { return r.kind }
+func (r fakeAddressedResource) GetAddr() string { return r.addr }
+
+type fakeWatcher struct {
+	events chan types.Event
+	done   chan struct{}
+}
+
+func newFakeWatcher() *fakeWatcher {
+	return &fakeWatcher{
+		events: make(chan types.Event, 8),
+		done:   make(chan struct{}),
+	}
+}
+
+func (w *fakeWatcher) Events() <-chan types.Event { return w.events }
+func (w *fakeWatcher) Done() <-chan struct{}      { return w.done }
+func (w *fakeWatcher) Error() error               { return nil }
+func (w *fakeWatcher) Close() error               { close(w.done); return nil }
+
+func TestWatchHeartbeatsPublishesOnAddressChange(t *testing.T) {
+	b := NewAddressChangeBroadcaster()
+	ch, unsubscribe, err := b.Subscribe(nil)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	watcher := newFakeWatcher()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.WatchHeartbeats(ctx, watcher)
+
+	watcher.events <- types.Event{
+		Type:     types.OpPut,
+		Resource: fakeAddressedResource{name: "host-1", kind: "node", addr: "10.0.0.1:3022"},
+	}
+	event := <-ch
+	require.Equal(t, "host-1", event.HostID)
+	require.Equal(t, "10.0.0.1:3022", event.NewAddr)
+
+	// Same address again should not republish.
+	watcher.events <- types.Event{
+		Type:     types.OpPut,
+		Resource: fakeAddressedResource{name: "host-1", kind: "node", addr: "10.0.0.1:3022"},
+	}
+	watcher.events <- types.Event{
+		Type:     types.OpPut,
+		Resource: fakeAddressedResource{name: "host-2", kind: "node", addr: "10.0.0.2:3022"},
+	}
+	event = <-ch
+	require.Equal(t, "host-2", event.HostID)
+
+	watcher.events <- types.Event{
+		Type:     types.OpDelete,
+		Resource: fakeAddressedResource{name: "host-1", kind: "node"},
+	}
+	event = <-ch
+	require.Equal(t, "host-1", event.HostID)
+	require.Equal(t, "10.0.0.1:3022", event.OldAddr)
+	require.Equal(t, "", event.NewAddr)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import "sync"
+
+// RoutingTable maps a server's host ID to the address currently used to
+// reach it. A peer proxy keeps one up to date by subscribing to
+// StreamServerAddresses, so dialing a server that just rebound its
+// address doesn't have to wait for the next watch cycle.
+type RoutingTable interface {
+	// UpdateServerAddr records addr as the current address for hostID.
+	UpdateServerAddr(hostID, addr string)
+	// RemoveServerAddr forgets hostID, e.g. after it's deleted.
+	RemoveServerAddr(hostID string)
+	// ServerAddr returns the address currently on file for hostID, and
+	// whether one is known at all.
+	ServerAddr(hostID string) (string, bool)
+}
+
+// routingTable is the default in-memory RoutingTable.
+type routingTable struct {
+	mu    sync.RWMutex
+	addrs map[string]string
+}
+
+// NewRoutingTable creates an empty RoutingTable.
+func NewRoutingTable() RoutingTable {
+	return &routingTable{addrs: make(map[string]string)}
+}
+
+func (t *routingTable) UpdateServerAddr(hostID, addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.addrs[hostID] = addr
+}
+
+func (t *routingTable) RemoveServerAddr(hostID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.addrs, hostID)
+}
+
+func (t *routingTable) ServerAddr(hostID string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	addr, ok := t.addrs[hostID]
+	return addr, ok
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutingTableUpdateAndRemove(t *testing.T) {
+	table := NewRoutingTable()
+
+	_, ok := table.ServerAddr("host-1")
+	require.False(t, ok)
+
+	table.UpdateServerAddr("host-1", "10.0.0.1:3022")
+	addr, ok := table.ServerAddr("host-1")
+	require.True(t, ok)
+	require.Equal(t, "10.0.0.1:3022", addr)
+
+	table.UpdateServerAddr("host-1", "10.0.0.2:3022")
+	addr, ok = table.ServerAddr("host-1")
+	require.True(t, ok)
+	require.Equal(t, "10.0.0.2:3022", addr)
+
+	table.RemoveServerAddr("host-1")
+	_, ok = table.ServerAddr("host-1")
+	require.False(t, ok)
+}
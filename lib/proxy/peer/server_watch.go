@@ -0,0 +1,40 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/sirupsen/logrus"
+)
+
+// StartAddressChangeWatch feeds this server's address-change broadcaster
+// from watcher in the background, so that every peer proxy subscribed via
+// StreamServerAddresses learns about a node/proxy/kube/database/app
+// address change without waiting for its own next heartbeat/watch cycle.
+// It returns once the watch goroutine has been started; the watch itself
+// runs until ctx is canceled or watcher closes. Callers should start this
+// once per proxy process, alongside the server-heartbeat watcher they
+// already open for the reverse tunnel subsystem.
+func (s *Server) StartAddressChangeWatch(ctx context.Context, watcher types.Watcher) {
+	go func() {
+		if err := s.addressChanges.WatchHeartbeats(ctx, watcher); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Warn("proxy-peer address-change watch exited")
+		}
+	}()
+}
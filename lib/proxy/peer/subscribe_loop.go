@@ -0,0 +1,65 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// subscribeRetryBackoff and subscribeMaxRetryBackoff bound how quickly
+// RunAddressSubscriptionLoop retries a dropped StreamServerAddresses
+// connection. They're vars, rather than consts, so tests don't have to
+// wait out a real backoff.
+var (
+	subscribeRetryBackoff    = time.Second
+	subscribeMaxRetryBackoff = 30 * time.Second
+)
+
+// RunAddressSubscriptionLoop keeps table up to date from a single peer's
+// StreamServerAddresses RPC, resuming from the last cursor it saw so a
+// reconnect to that peer doesn't lose any address changes. It retries
+// with exponential backoff until ctx is canceled, and is meant to be
+// started once per peer connection a proxy opens under the proxy-peering
+// tunnel strategy.
+func RunAddressSubscriptionLoop(ctx context.Context, client proto.ProxyServiceClient, table RoutingTable) {
+	var afterCursor *uint64
+	backoff := subscribeRetryBackoff
+
+	for ctx.Err() == nil {
+		lastCursor, err := SubscribeServerAddresses(ctx, client, table, afterCursor)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logrus.WithError(err).Warn("proxy-peer address subscription disconnected, retrying")
+		}
+		afterCursor = &lastCursor
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > subscribeMaxRetryBackoff {
+			backoff = subscribeMaxRetryBackoff
+		}
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/lib/proxy/peer"
+)
+
+// GetProxyPeerRoutingTable returns the RoutingTable this process's
+// proxy-peer connections keep up to date via
+// peer.RunAddressSubscriptionLoop, so a caller can look up the address
+// currently on file for a host without waiting for this process's own
+// next heartbeat/watch cycle. It's nil unless this process is running a
+// proxy under the proxy-peering tunnel strategy.
+func (process *TeleportProcess) GetProxyPeerRoutingTable() peer.RoutingTable {
+	return process.proxyPeerRoutingTable
+}
+
+// startProxyPeerAddressSubscriptions builds this process's proxy-peer
+// routing table and, for every peer proxy connection passed in, starts a
+// peer.RunAddressSubscriptionLoop that keeps it current. It's meant to be
+// called once, from the same proxy-peering setup step that dials these
+// peer connections in the first place.
+func (process *TeleportProcess) startProxyPeerAddressSubscriptions(ctx context.Context, peerClients []proto.ProxyServiceClient) {
+	process.proxyPeerRoutingTable = peer.NewRoutingTable()
+	for _, client := range peerClients {
+		go peer.RunAddressSubscriptionLoop(ctx, client, process.proxyPeerRoutingTable)
+	}
+}
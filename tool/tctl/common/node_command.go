@@ -62,6 +62,16 @@ type NodeCommand struct {
 	predicateExpr  string
 	labels         string
 
+	// sortField is the resource field results are sorted by, with an
+	// optional ":desc" suffix, e.g. "name:desc".
+	sortField string
+	// limit caps the number of resources returned by a single ls call.
+	// 0 means "use the server default".
+	limit int
+	// pageToken resumes a previous ls call from where it left off, using
+	// the nextKey printed by that call.
+	pageToken string
+
 	// ls output format -- text or json
 	lsFormat string
 
@@ -94,6 +104,9 @@ func (c *NodeCommand) Initialize(app *kingpin.Application, config *service.Confi
 	c.nodeList.Arg("labels", labelHelp).StringVar(&c.labels)
 	c.nodeList.Flag("search", searchHelp).StringVar(&c.searchKeywords)
 	c.nodeList.Flag("query", queryHelp).StringVar(&c.predicateExpr)
+	c.nodeList.Flag("sort", "Sort by resource field, optionally followed by \":desc\" for descending order, e.g. hostname:desc").StringVar(&c.sortField)
+	c.nodeList.Flag("limit", "Maximum number of nodes to return in a single page").IntVar(&c.limit)
+	c.nodeList.Flag("page-token", "Resume listing from the nextKey returned by a previous call").StringVar(&c.pageToken)
 }
 
 // TryRun takes the CLI command as an argument (like "nodes ls") and executes it.
@@ -131,7 +144,8 @@ Please note:
 
   - This invitation token will expire in {{.minutes}} minutes
   - {{.auth_server}} must be reachable from the new node
-`))
+{{if .build_date}}  - This invite was generated by an auth server built on {{.build_date}} ({{.git_commit}})
+{{end}}`))
 
 // Invite generates a token which can be used to add another SSH node
 // to a cluster
@@ -189,12 +203,18 @@ func (c *NodeCommand) Invite(client auth.ClientI) error {
 					authServer = proxies[0].GetPublicAddr()
 				}
 			}
+			var buildDate, gitCommit string
+			if err == nil {
+				buildDate, gitCommit = pingResponse.BuildDate, pingResponse.GitCommit
+			}
 			return nodeMessageTemplate.Execute(os.Stdout, map[string]interface{}{
 				"token":       token,
 				"minutes":     int(c.ttl.Minutes()),
 				"roles":       strings.ToLower(roles.String()),
 				"ca_pins":     caPins,
 				"auth_server": authServer,
+				"build_date":  buildDate,
+				"git_commit":  gitCommit,
 			})
 		}
 	} else {
@@ -220,20 +240,53 @@ func (c *NodeCommand) ListActive(clt auth.ClientI) error {
 		return trace.Wrap(err)
 	}
 
-	var nodes []types.Server
-	resources, err := client.GetResourcesWithFilters(ctx, clt, proto.ListResourcesRequest{
+	sortBy, err := parseSortField(c.sortField)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	req := proto.ListResourcesRequest{
 		ResourceType:        types.KindNode,
 		Namespace:           c.namespace,
 		Labels:              labels,
 		PredicateExpression: c.predicateExpr,
 		SearchKeywords:      libclient.ParseSearchKeywords(c.searchKeywords, ','),
-	})
+		SortBy:              sortBy,
+		Limit:               int32(c.limit),
+		StartKey:            c.pageToken,
+	}
+
+	// A --limit or --page-token means the caller wants to walk the
+	// inventory page by page rather than have the client pull every
+	// matching resource into memory before printing anything, which is
+	// the only option GetResourcesWithFilters gives us.
+	paginated := c.limit > 0 || c.pageToken != ""
+
+	var nodes []types.Server
+	var nextKey string
+	if paginated {
+		var resp *proto.ListResourcesResponse
+		resp, err = clt.ListResources(ctx, req)
+		if err == nil {
+			nodes, err = types.ResourcesWithLabels(resp.Resources).AsServers()
+			nextKey = resp.NextKey
+		}
+	} else {
+		var resources types.ResourcesWithLabels
+		resources, err = client.GetResourcesWithFilters(ctx, clt, req)
+		if err == nil {
+			nodes, err = types.ResourcesWithLabels(resources).AsServers()
+		}
+	}
+
 	switch {
 	// Underlying ListResources for nodes not available, use fallback.
-	// Using filter flags with older auth will silently do nothing.
 	//
 	// DELETE IN 11.0.0
 	case trace.IsNotImplemented(err):
+		if c.sortField != "" || paginated {
+			log.Warnf("This auth server does not support --sort, --limit, or --page-token; falling back to an unsorted, unpaginated listing of every node.")
+		}
 		nodes, err = clt.GetNodes(ctx, c.namespace)
 		if err != nil {
 			return trace.Wrap(err)
@@ -243,19 +296,17 @@ func (c *NodeCommand) ListActive(clt auth.ClientI) error {
 			return trace.Wrap(utils.PredicateError{Err: err})
 		}
 		return trace.Wrap(err)
-	default:
-		nodes, err = types.ResourcesWithLabels(resources).AsServers()
-		if err != nil {
-			return trace.Wrap(err)
-		}
 	}
 
-	coll := &serverCollection{servers: nodes, verbose: c.verbose}
+	coll := &serverCollection{servers: nodes, verbose: c.verbose, nextKey: nextKey}
 	switch c.lsFormat {
 	case teleport.Text:
 		if err := coll.writeText(os.Stdout); err != nil {
 			return trace.Wrap(err)
 		}
+		if nextKey != "" {
+			fmt.Fprintf(os.Stdout, "\nMore nodes available, run again with --page-token=%s to see them.\n", nextKey)
+		}
 	case teleport.YAML:
 		if err := coll.writeYaml(os.Stdout); err != nil {
 			return trace.Wrap(err)
@@ -269,3 +320,26 @@ func (c *NodeCommand) ListActive(clt auth.ClientI) error {
 	}
 	return nil
 }
+
+// parseSortField parses a "--sort" flag value of the form "field" or
+// "field:desc" into a types.SortBy. An empty input returns a nil SortBy,
+// leaving the sort order up to the backend.
+func parseSortField(sortField string) (*types.SortBy, error) {
+	if sortField == "" {
+		return nil, nil
+	}
+	field := sortField
+	order := ""
+	if idx := strings.Index(sortField, ":"); idx != -1 {
+		field, order = sortField[:idx], sortField[idx+1:]
+	}
+	isDesc := false
+	switch order {
+	case "", "asc":
+	case "desc":
+		isDesc = true
+	default:
+		return nil, trace.BadParameter("invalid sort order %q, expected \"asc\" or \"desc\"", order)
+	}
+	return &types.SortBy{Field: field, IsDesc: isDesc}, nil
+}
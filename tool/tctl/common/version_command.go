@@ -0,0 +1,92 @@
+/*
+Copyright 2022 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gravitational/kingpin"
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/service"
+)
+
+// VersionCommand implements the `tctl version` command.
+type VersionCommand struct {
+	config *service.Config
+
+	// remote, when set, also dials the auth server and prints its build
+	// info alongside the local binary's, so operators can spot drift
+	// across a fleet before rolling an upgrade.
+	remote bool
+
+	version *kingpin.CmdClause
+}
+
+// Initialize allows VersionCommand to plug itself into the CLI parser.
+func (c *VersionCommand) Initialize(app *kingpin.Application, config *service.Config) {
+	c.config = config
+
+	c.version = app.Command("version", "Print the version of your tctl binary")
+	c.version.Flag("remote", "Also print the connected auth server's build info").BoolVar(&c.remote)
+}
+
+// TryRun takes the CLI command as an argument (like "version") and executes it.
+func (c *VersionCommand) TryRun(cmd string, client auth.ClientI) (match bool, err error) {
+	switch cmd {
+	case c.version.FullCommand():
+		err = c.Run(client)
+	default:
+		return false, nil
+	}
+	return true, trace.Wrap(err)
+}
+
+// Run prints the local tctl build info and, if --remote was given, the
+// connected auth server's build info alongside it.
+func (c *VersionCommand) Run(client auth.ClientI) error {
+	fmt.Printf("Teleport v%s git:%s go:%s\n", teleport.Version, teleport.GitCommit, teleport.GoVersion)
+	if teleport.BuildDate != "" {
+		fmt.Printf("Built: %s\n", teleport.BuildDate)
+	}
+
+	if !c.remote {
+		return nil
+	}
+
+	resp, err := client.Ping(context.TODO())
+	if err != nil {
+		return trace.Wrap(err, "pinging auth server")
+	}
+
+	fmt.Println()
+	fmt.Println("Auth server:")
+	fmt.Printf("  Version:    %s\n", resp.ServerVersion)
+	fmt.Printf("  Git commit: %s\n", resp.GitCommit)
+	fmt.Printf("  Go version: %s\n", resp.GoVersion)
+	fmt.Printf("  Built:      %s\n", resp.BuildDate)
+
+	if resp.BuildDate != teleport.BuildDate || resp.GitCommit != teleport.GitCommit {
+		fmt.Println()
+		fmt.Println("local and remote builds differ")
+	}
+
+	return nil
+}